@@ -13,6 +13,7 @@ import (
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -70,6 +71,34 @@ func findBaseURL(sd *sdp.SessionDescription, res *base.Response, u *url.URL) (*u
 	return u, nil
 }
 
+// clientValidateInterleavedIDs checks that interleaved IDs are valid channels and are not in use.
+// RTP and RTCP channels are not required to be even, consecutive or in any particular order.
+func clientValidateInterleavedIDs(ids [2]int, tcpMediasByChannel map[int]*clientMedia) error {
+	if ids[0] < 0 || ids[0] > 255 ||
+		ids[1] < 0 || ids[1] > 255 ||
+		ids[0] == ids[1] {
+		return liberrors.ErrClientTransportHeaderInvalidInterleavedIDs{}
+	}
+
+	for _, id := range ids {
+		if _, ok := tcpMediasByChannel[id]; ok {
+			return liberrors.ErrClientTransportHeaderInterleavedIDsAlreadyUsed{}
+		}
+	}
+
+	return nil
+}
+
+func clientFindFreeChannelPair(tcpMediasByChannel map[int]*clientMedia) [2]int {
+	for i := 0; ; i += 2 {
+		_, ok1 := tcpMediasByChannel[i]
+		_, ok2 := tcpMediasByChannel[i+1]
+		if !ok1 && !ok2 {
+			return [2]int{i, i + 1}
+		}
+	}
+}
+
 func resetMediaControls(ms media.Medias) {
 	for i, media := range ms {
 		media.Control = "trackID=" + strconv.FormatInt(int64(i), 10)
@@ -132,7 +161,8 @@ type playReq struct {
 }
 
 type recordReq struct {
-	res chan clientRes
+	rtpInfo map[*media.Media]*headers.RTPInfoEntry
+	res     chan clientRes
 }
 
 type pauseReq struct {
@@ -152,6 +182,10 @@ type clientRes struct {
 type ClientLogFunc func(level LogLevel, format string, args ...interface{})
 
 // Client is a RTSP client.
+//
+// The underlying transport can be replaced with any net.Conn and net.PacketConn
+// (for instance, in-memory pipes or tunnels) through DialContext and ListenPacket;
+// there's no dedicated transport interface.
 type Client struct {
 	//
 	// RTSP parameters (all optional)
@@ -204,6 +238,8 @@ type Client struct {
 	// system functions (all optional)
 	//
 	// function used to initialize the TCP client.
+	// It can return any net.Conn, including in-memory or custom links;
+	// connections whose addresses don't contain an IP can only use the TCP transport.
 	// It defaults to (&net.Dialer{}).DialContext.
 	DialContext func(ctx context.Context, network, address string) (net.Conn, error)
 	// function used to initialize UDP listeners.
@@ -219,6 +255,11 @@ type Client struct {
 	OnResponse func(*base.Response)
 	// called when the transport protocol changes.
 	OnTransportSwitch func(err error)
+	// called when a media is being setup with the TCP transport.
+	// It must return the interleaved IDs of RTP and RTCP packets requested to the server,
+	// that can reply with different ones (see InterleavedIDs()).
+	// It defaults to the first free pair of consecutive channels.
+	OnSetupInterleavedIDs func(*media.Media) [2]int
 	// called when the client detects lost packets.
 	OnPacketLost func(err error)
 	// called when a non-fatal decode error occurs.
@@ -250,15 +291,20 @@ type Client struct {
 	lastDescribeURL    *url.URL
 	baseURL            *url.URL
 	effectiveTransport *Transport
+	announcedMedias    media.Medias
 	medias             map[*media.Media]*clientMedia
 	tcpMediasByChannel map[int]*clientMedia
 	lastRange          *headers.Range
+	recordStarted      bool
+	recordStartTime    time.Time
+	recordDuration     time.Duration
 	checkStreamTimer   *time.Timer
 	checkStreamInitial bool
 	tcpLastFrameTime   *int64
 	keepaliveTimer     *time.Timer
 	closeError         error
 	writer             writer
+	writeMutex         sync.RWMutex // protects the write state from WritePacket*()
 
 	// connCloser channels
 	connCloserTerminate chan struct{}
@@ -337,6 +383,11 @@ func (c *Client) Start(scheme string, host string) error {
 		c.OnTransportSwitch = func(err error) {
 		}
 	}
+	if c.OnSetupInterleavedIDs == nil {
+		c.OnSetupInterleavedIDs = func(*media.Media) [2]int {
+			return clientFindFreeChannelPair(c.tcpMediasByChannel)
+		}
+	}
 	if c.Log != nil && c.OnPacketLost == nil {
 		c.OnPacketLost = func(err error) {
 			c.Log(LogLevelWarn, "%v", err)
@@ -474,7 +525,7 @@ func (c *Client) runInner() error {
 			req.res <- clientRes{res: res, err: err}
 
 		case req := <-c.record:
-			res, err := c.doRecord()
+			res, err := c.doRecord(req.rtpInfo)
 			req.res <- clientRes{res: res, err: err}
 
 		case req := <-c.pause:
@@ -607,8 +658,12 @@ func (c *Client) reset() {
 	c.useGetParameter = false
 	c.baseURL = nil
 	c.effectiveTransport = nil
+	c.announcedMedias = nil
 	c.medias = nil
 	c.tcpMediasByChannel = nil
+	c.writer.buffer = nil
+	c.recordStarted = false
+	c.recordDuration = 0
 }
 
 func (c *Client) checkState(allowed map[clientState]struct{}) error {
@@ -690,6 +745,9 @@ func (c *Client) trySwitchingProtocol2(medi *media.Media, baseURL *url.URL) (*ba
 }
 
 func (c *Client) playRecordStart() {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
 	// stop connCloser
 	c.connCloserStop()
 
@@ -711,7 +769,11 @@ func (c *Client) playRecordStart() {
 		}
 	}
 
-	if c.state == clientStatePlay {
+	// when resuming, the buffer is reused instead of being replaced,
+	// since WritePacket*() can be called in parallel.
+	if c.writer.buffer != nil {
+		c.writer.buffer.Reset()
+	} else if c.state == clientStatePlay {
 		// when reading, buffer is only used to send RTCP receiver reports,
 		// that are much smaller than RTP packets and are sent at a fixed interval.
 		// decrease RAM consumption by allocating less buffers.
@@ -753,19 +815,12 @@ func (c *Client) runReader() {
 				}
 
 				if fr, ok := what.(*base.InterleavedFrame); ok {
-					channel := fr.Channel
-					isRTP := true
-					if (channel % 2) != 0 {
-						channel--
-						isRTP = false
-					}
-
-					media, ok := c.tcpMediasByChannel[channel]
+					media, ok := c.tcpMediasByChannel[fr.Channel]
 					if !ok {
 						continue
 					}
 
-					if isRTP {
+					if fr.Channel == media.tcpRTPChannel {
 						err = media.readRTP(fr.Payload)
 					} else {
 						err = media.readRTCP(fr.Payload)
@@ -780,6 +835,9 @@ func (c *Client) runReader() {
 }
 
 func (c *Client) playRecordStop(isClosing bool) {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
 	// stop reader
 	if c.readerErr != nil {
 		c.nconn.SetReadDeadline(time.Now())
@@ -1117,14 +1175,58 @@ func (c *Client) Describe(u *url.URL) (media.Medias, *url.URL, *base.Response, e
 	}
 }
 
+func checkReannouncedMedias(prevMedias media.Medias, medias media.Medias) error {
+	if len(medias) != len(prevMedias) {
+		return fmt.Errorf("medias can't be added or removed")
+	}
+
+	for i, medi := range medias {
+		prevMedi := prevMedias[i]
+
+		if medi.Type != prevMedi.Type {
+			return fmt.Errorf("media type can't be changed")
+		}
+
+		if len(medi.Formats) != len(prevMedi.Formats) {
+			return fmt.Errorf("formats can't be added or removed")
+		}
+
+		for j, forma := range medi.Formats {
+			prevForma := prevMedi.Formats[j]
+
+			if forma.PayloadType() != prevForma.PayloadType() {
+				return fmt.Errorf("payload type %d can't be changed", prevForma.PayloadType())
+			}
+
+			if forma.ClockRate() != prevForma.ClockRate() {
+				return fmt.Errorf("clock rate of payload type %d can't be changed",
+					forma.PayloadType())
+			}
+		}
+	}
+
+	return nil
+}
+
 func (c *Client) doAnnounce(u *url.URL, medias media.Medias) (*base.Response, error) {
 	err := c.checkState(map[clientState]struct{}{
-		clientStateInitial: {},
+		clientStateInitial:   {},
+		clientStatePreRecord: {},
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	// an ANNOUNCE sent inside an existing session only updates the SDP.
+	// The state of medias and formats is kept, since it can be accessed
+	// by WritePacketRTP() in parallel, therefore medias must be compatible.
+	if c.state == clientStatePreRecord {
+		err := checkReannouncedMedias(c.announcedMedias, medias)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	resetMediaControls(medias)
 
 	byts, err := medias.Marshal(false).Marshal()
@@ -1150,6 +1252,10 @@ func (c *Client) doAnnounce(u *url.URL, medias media.Medias) (*base.Response, er
 		}
 	}
 
+	if c.state == clientStateInitial {
+		c.announcedMedias = medias
+	}
+
 	c.baseURL = u.Clone()
 	c.state = clientStatePreRecord
 
@@ -1157,6 +1263,12 @@ func (c *Client) doAnnounce(u *url.URL, medias media.Medias) (*base.Response, er
 }
 
 // Announce writes an ANNOUNCE request and reads a Response.
+// It can also be called after Setup(), or after Pause() while recording,
+// in order to update the SDP of the session (for instance, when codec parameters change);
+// medias must be the same number and types of the ones previously announced,
+// and must contain formats with the same payload types and clock rates.
+// In this case, medias are only used to generate the SDP, and WritePacketRTP()
+// and similar methods must still be called with the medias previously passed to Setup().
 func (c *Client) Announce(u *url.URL, medias media.Medias) (*base.Response, error) {
 	cres := make(chan clientRes)
 	select {
@@ -1194,6 +1306,16 @@ func (c *Client) doSetup(
 		c.effectiveTransport = &v
 	}
 
+	// use TCP if the connection is not IP-based
+	if c.nconn != nil && tcpAddr(c.nconn.RemoteAddr()).IP == nil {
+		if c.Transport != nil && *c.Transport != TransportTCP {
+			return nil, liberrors.ErrClientUDPNotSupportedByConn{}
+		}
+
+		v := TransportTCP
+		c.effectiveTransport = &v
+	}
+
 	requestedTransport := func() Transport {
 		// transport set by previous Setup() or trySwitchingProtocol()
 		if c.effectiveTransport != nil {
@@ -1254,8 +1376,15 @@ func (c *Client) doSetup(
 		v1 := headers.TransportDeliveryUnicast
 		th.Delivery = &v1
 		th.Protocol = headers.TransportProtocolTCP
-		mediaCount := len(c.medias)
-		th.InterleavedIDs = &[2]int{(mediaCount * 2), (mediaCount * 2) + 1}
+
+		ids := c.OnSetupInterleavedIDs(medi)
+		err := clientValidateInterleavedIDs(ids, c.tcpMediasByChannel)
+		if err != nil {
+			cm.close()
+			return nil, err
+		}
+
+		th.InterleavedIDs = &ids
 	}
 
 	mediaURL, err := medi.URL(baseURL)
@@ -1332,7 +1461,7 @@ func (c *Client) doSetup(
 		if thRes.Source != nil {
 			cm.udpRTPListener.readIP = *thRes.Source
 		} else {
-			cm.udpRTPListener.readIP = c.nconn.RemoteAddr().(*net.TCPAddr).IP
+			cm.udpRTPListener.readIP = tcpAddr(c.nconn.RemoteAddr()).IP
 		}
 
 		if thRes.ServerPorts != nil {
@@ -1340,8 +1469,8 @@ func (c *Client) doSetup(
 				cm.udpRTPListener.readPort = thRes.ServerPorts[0]
 			}
 			cm.udpRTPListener.writeAddr = &net.UDPAddr{
-				IP:   c.nconn.RemoteAddr().(*net.TCPAddr).IP,
-				Zone: c.nconn.RemoteAddr().(*net.TCPAddr).Zone,
+				IP:   tcpAddr(c.nconn.RemoteAddr()).IP,
+				Zone: tcpAddr(c.nconn.RemoteAddr()).Zone,
 				Port: thRes.ServerPorts[0],
 			}
 		}
@@ -1349,7 +1478,7 @@ func (c *Client) doSetup(
 		if thRes.Source != nil {
 			cm.udpRTCPListener.readIP = *thRes.Source
 		} else {
-			cm.udpRTCPListener.readIP = c.nconn.RemoteAddr().(*net.TCPAddr).IP
+			cm.udpRTCPListener.readIP = tcpAddr(c.nconn.RemoteAddr()).IP
 		}
 
 		if thRes.ServerPorts != nil {
@@ -1357,8 +1486,8 @@ func (c *Client) doSetup(
 				cm.udpRTCPListener.readPort = thRes.ServerPorts[1]
 			}
 			cm.udpRTCPListener.writeAddr = &net.UDPAddr{
-				IP:   c.nconn.RemoteAddr().(*net.TCPAddr).IP,
-				Zone: c.nconn.RemoteAddr().(*net.TCPAddr).Zone,
+				IP:   tcpAddr(c.nconn.RemoteAddr()).IP,
+				Zone: tcpAddr(c.nconn.RemoteAddr()).Zone,
 				Port: thRes.ServerPorts[1],
 			}
 		}
@@ -1385,14 +1514,14 @@ func (c *Client) doSetup(
 			return nil, err
 		}
 
-		cm.udpRTPListener.readIP = c.nconn.RemoteAddr().(*net.TCPAddr).IP
+		cm.udpRTPListener.readIP = tcpAddr(c.nconn.RemoteAddr()).IP
 		cm.udpRTPListener.readPort = thRes.Ports[0]
 		cm.udpRTPListener.writeAddr = &net.UDPAddr{
 			IP:   *thRes.Destination,
 			Port: thRes.Ports[0],
 		}
 
-		cm.udpRTCPListener.readIP = c.nconn.RemoteAddr().(*net.TCPAddr).IP
+		cm.udpRTCPListener.readIP = tcpAddr(c.nconn.RemoteAddr()).IP
 		cm.udpRTCPListener.readPort = thRes.Ports[1]
 		cm.udpRTCPListener.writeAddr = &net.UDPAddr{
 			IP:   *thRes.Destination,
@@ -1412,15 +1541,11 @@ func (c *Client) doSetup(
 			return nil, liberrors.ErrClientTransportHeaderNoInterleavedIDs{}
 		}
 
-		if (thRes.InterleavedIDs[0]%2) != 0 ||
-			(thRes.InterleavedIDs[0]+1) != thRes.InterleavedIDs[1] {
-			return nil, liberrors.ErrClientTransportHeaderInvalidInterleavedIDs{}
-		}
-
-		if _, ok := c.tcpMediasByChannel[thRes.InterleavedIDs[0]]; ok {
-			return &base.Response{
-				StatusCode: base.StatusBadRequest,
-			}, liberrors.ErrClientTransportHeaderInterleavedIDsAlreadyUsed{}
+		// honor any valid pairing returned by the server,
+		// even if channels are not even or consecutive.
+		err := clientValidateInterleavedIDs(*thRes.InterleavedIDs, c.tcpMediasByChannel)
+		if err != nil {
+			return nil, err
 		}
 
 		if c.tcpMediasByChannel == nil {
@@ -1428,7 +1553,9 @@ func (c *Client) doSetup(
 		}
 
 		c.tcpMediasByChannel[thRes.InterleavedIDs[0]] = cm
-		cm.tcpChannel = thRes.InterleavedIDs[0]
+		c.tcpMediasByChannel[thRes.InterleavedIDs[1]] = cm
+		cm.tcpRTPChannel = thRes.InterleavedIDs[0]
+		cm.tcpRTCPChannel = thRes.InterleavedIDs[1]
 	}
 
 	if c.medias == nil {
@@ -1476,6 +1603,18 @@ func (c *Client) Setup(
 	}
 }
 
+// InterleavedIDs returns the interleaved IDs of RTP and RTCP packets of a media,
+// as assigned by the server during Setup().
+// It returns nil if the media has not been setup with the TCP transport.
+func (c *Client) InterleavedIDs(medi *media.Media) *[2]int {
+	cm, ok := c.medias[medi]
+	if !ok || c.tcpMediasByChannel[cm.tcpRTPChannel] != cm {
+		return nil
+	}
+
+	return &[2]int{cm.tcpRTPChannel, cm.tcpRTCPChannel}
+}
+
 // SetupAll setups all the given medias.
 func (c *Client) SetupAll(medias media.Medias, baseURL *url.URL) error {
 	for _, m := range medias {
@@ -1556,7 +1695,7 @@ func (c *Client) Play(ra *headers.Range) (*base.Response, error) {
 	}
 }
 
-func (c *Client) doRecord() (*base.Response, error) {
+func (c *Client) doRecord(rtpInfo map[*media.Media]*headers.RTPInfoEntry) (*base.Response, error) {
 	err := c.checkState(map[clientState]struct{}{
 		clientStatePreRecord: {},
 	})
@@ -1564,9 +1703,33 @@ func (c *Client) doRecord() (*base.Response, error) {
 		return nil, err
 	}
 
+	header := make(base.Header)
+
+	// when resuming, tell the server where the recording resumes from.
+	if c.recordStarted {
+		ra := &headers.Range{
+			Value: &headers.RangeNPT{
+				Start: c.recordDuration,
+			},
+		}
+		header["Range"] = ra.Marshal()
+	}
+
+	if rtpInfo != nil {
+		ri, err := c.recordRTPInfo(rtpInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ri) > 0 {
+			header["RTP-Info"] = ri.Marshal()
+		}
+	}
+
 	res, err := c.do(&base.Request{
 		Method: base.Record,
 		URL:    c.baseURL,
+		Header: header,
 	}, false, false)
 	if err != nil {
 		return nil, err
@@ -1578,18 +1741,61 @@ func (c *Client) doRecord() (*base.Response, error) {
 		}
 	}
 
+	c.recordStarted = true
+	c.recordStartTime = time.Now()
 	c.state = clientStateRecord
 	c.playRecordStart()
 
 	return nil, nil
 }
 
+func (c *Client) recordRTPInfo(rtpInfo map[*media.Media]*headers.RTPInfoEntry) (headers.RTPInfo, error) {
+	for medi := range rtpInfo {
+		if _, ok := c.medias[medi]; !ok {
+			return nil, fmt.Errorf("RTP-Info contains a media that has not been setup")
+		}
+	}
+
+	var ri headers.RTPInfo
+
+	// follow the order of the SDP
+	for _, medi := range c.announcedMedias {
+		entry, ok := rtpInfo[medi]
+		if !ok {
+			continue
+		}
+
+		u, err := medi.URL(c.baseURL)
+		if err != nil {
+			return nil, err
+		}
+
+		ri = append(ri, &headers.RTPInfoEntry{
+			URL:            u.String(),
+			SequenceNumber: entry.SequenceNumber,
+			Timestamp:      entry.Timestamp,
+		})
+	}
+
+	return ri, nil
+}
+
 // Record writes a RECORD request and reads a Response.
-// This can be called only after Announce() and Setup().
+// This can be called only after Announce() and Setup(), or after Pause() to resume recording.
+// When resuming, the request contains the Range header.
 func (c *Client) Record() (*base.Response, error) {
+	return c.RecordWithRTPInfo(nil)
+}
+
+// RecordWithRTPInfo is like Record(), but also sends the RTP-Info header,
+// that contains the sequence number and timestamp of the first RTP packet
+// that is going to be written after the request, for each media.
+// It is meant to be used when resuming, since sequence numbers and timestamps
+// are chosen by the caller. Medias are the ones passed to Setup(); URLs of entries are ignored.
+func (c *Client) RecordWithRTPInfo(rtpInfo map[*media.Media]*headers.RTPInfoEntry) (*base.Response, error) {
 	cres := make(chan clientRes)
 	select {
-	case c.record <- recordReq{res: cres}:
+	case c.record <- recordReq{rtpInfo: rtpInfo, res: cres}:
 		res := <-cres
 		return res.res, res.err
 
@@ -1614,6 +1820,7 @@ func (c *Client) doPause() (*base.Response, error) {
 	case clientStatePlay:
 		c.state = clientStatePrePlay
 	case clientStateRecord:
+		c.recordDuration += time.Since(c.recordStartTime)
 		c.state = clientStatePreRecord
 	}
 
@@ -1700,6 +1907,9 @@ func (c *Client) WritePacketRTP(medi *media.Media, pkt *rtp.Packet) error {
 
 // WritePacketRTPWithNTP writes a RTP packet to the media stream.
 func (c *Client) WritePacketRTPWithNTP(medi *media.Media, pkt *rtp.Packet, ntp time.Time) error {
+	c.writeMutex.RLock()
+	defer c.writeMutex.RUnlock()
+
 	cm := c.medias[medi]
 	ct := cm.formats[pkt.PayloadType]
 	return ct.writePacketRTPWithNTP(pkt, ntp)
@@ -1707,6 +1917,9 @@ func (c *Client) WritePacketRTPWithNTP(medi *media.Media, pkt *rtp.Packet, ntp t
 
 // WritePacketRTCP writes a RTCP packet to the media stream.
 func (c *Client) WritePacketRTCP(medi *media.Media, pkt rtcp.Packet) error {
+	c.writeMutex.RLock()
+	defer c.writeMutex.RUnlock()
+
 	cm := c.medias[medi]
 	return cm.writePacketRTCP(pkt)
 }
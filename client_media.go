@@ -16,7 +16,8 @@ type clientMedia struct {
 	c                      *Client
 	media                  *media.Media
 	formats                map[uint8]*clientFormat
-	tcpChannel             int
+	tcpRTPChannel          int
+	tcpRTCPChannel         int
 	udpRTPListener         *clientUDPListener
 	udpRTCPListener        *clientUDPListener
 	tcpRTPFrame            *base.InterleavedFrame
@@ -116,8 +117,8 @@ func (cm *clientMedia) start() {
 			cm.readRTCP = cm.readRTCPTCPRecord
 		}
 
-		cm.tcpRTPFrame = &base.InterleavedFrame{Channel: cm.tcpChannel}
-		cm.tcpRTCPFrame = &base.InterleavedFrame{Channel: cm.tcpChannel + 1}
+		cm.tcpRTPFrame = &base.InterleavedFrame{Channel: cm.tcpRTPChannel}
+		cm.tcpRTCPFrame = &base.InterleavedFrame{Channel: cm.tcpRTCPChannel}
 		cm.tcpBuffer = make([]byte, udpMaxPayloadSize+4)
 	}
 
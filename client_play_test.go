@@ -1608,6 +1608,155 @@ func TestClientPlayDifferentInterleavedIDs(t *testing.T) {
 	<-packetRecv
 }
 
+func TestClientPlayNonContiguousInterleavedIDs(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer l.Close()
+
+	serverDone := make(chan struct{})
+	defer func() { <-serverDone }()
+	go func() {
+		defer close(serverDone)
+
+		nconn, err := l.Accept()
+		require.NoError(t, err)
+		defer nconn.Close()
+		conn := conn.NewConn(nconn)
+
+		req, err := conn.ReadRequest()
+		require.NoError(t, err)
+		require.Equal(t, base.Options, req.Method)
+
+		err = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+			Header: base.Header{
+				"Public": base.HeaderValue{strings.Join([]string{
+					string(base.Describe),
+					string(base.Setup),
+					string(base.Play),
+				}, ", ")},
+			},
+		})
+		require.NoError(t, err)
+
+		req, err = conn.ReadRequest()
+		require.NoError(t, err)
+		require.Equal(t, base.Describe, req.Method)
+
+		medias := media.Medias{testH264Media}
+		resetMediaControls(medias)
+
+		err = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+			Header: base.Header{
+				"Content-Type": base.HeaderValue{"application/sdp"},
+				"Content-Base": base.HeaderValue{"rtsp://localhost:8554/teststream/"},
+			},
+			Body: mustMarshalMedias(medias),
+		})
+		require.NoError(t, err)
+
+		req, err = conn.ReadRequest()
+		require.NoError(t, err)
+		require.Equal(t, base.Setup, req.Method)
+
+		var inTH headers.Transport
+		err = inTH.Unmarshal(req.Header["Transport"])
+		require.NoError(t, err)
+		require.Equal(t, &[2]int{10, 20}, inTH.InterleavedIDs)
+
+		th := headers.Transport{
+			Delivery: func() *headers.TransportDelivery {
+				v := headers.TransportDeliveryUnicast
+				return &v
+			}(),
+			Protocol:       headers.TransportProtocolTCP,
+			InterleavedIDs: &[2]int{7, 4},
+		}
+
+		err = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+			Header: base.Header{
+				"Transport": th.Marshal(),
+			},
+		})
+		require.NoError(t, err)
+
+		req, err = conn.ReadRequest()
+		require.NoError(t, err)
+		require.Equal(t, base.Play, req.Method)
+
+		err = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+		})
+		require.NoError(t, err)
+
+		err = conn.WriteInterleavedFrame(&base.InterleavedFrame{
+			Channel: 4,
+			Payload: testRTCPPacketMarshaled,
+		}, make([]byte, 1024))
+		require.NoError(t, err)
+
+		err = conn.WriteInterleavedFrame(&base.InterleavedFrame{
+			Channel: 7,
+			Payload: testRTPPacketMarshaled,
+		}, make([]byte, 1024))
+		require.NoError(t, err)
+
+		req, err = conn.ReadRequest()
+		require.NoError(t, err)
+		require.Equal(t, base.Teardown, req.Method)
+
+		err = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+		})
+		require.NoError(t, err)
+	}()
+
+	rtcpRecv := make(chan struct{})
+	rtpRecv := make(chan struct{})
+
+	c := Client{
+		Transport: func() *Transport {
+			v := TransportTCP
+			return &v
+		}(),
+		OnSetupInterleavedIDs: func(*media.Media) [2]int {
+			return [2]int{10, 20}
+		},
+	}
+
+	u, err := url.Parse("rtsp://localhost:8554/teststream")
+	require.NoError(t, err)
+
+	err = c.Start(u.Scheme, u.Host)
+	require.NoError(t, err)
+	defer c.Close()
+
+	medias, baseURL, _, err := c.Describe(u)
+	require.NoError(t, err)
+
+	err = c.SetupAll(medias, baseURL)
+	require.NoError(t, err)
+	require.Equal(t, &[2]int{7, 4}, c.InterleavedIDs(medias[0]))
+
+	c.OnPacketRTCPAny(func(medi *media.Media, pkt rtcp.Packet) {
+		require.Equal(t, &testRTCPPacket, pkt)
+		close(rtcpRecv)
+	})
+
+	c.OnPacketRTPAny(func(medi *media.Media, forma formats.Format, pkt *rtp.Packet) {
+		require.Equal(t, &testRTPPacket, pkt)
+		close(rtpRecv)
+	})
+
+	_, err = c.Play(nil)
+	require.NoError(t, err)
+
+	<-rtcpRecv
+	<-rtpRecv
+}
+
 func TestClientPlayRedirect(t *testing.T) {
 	for _, withCredentials := range []bool{false, true} {
 		runName := "WithoutCredentials"
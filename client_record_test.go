@@ -750,6 +750,399 @@ func TestClientRecordPauseParallel(t *testing.T) {
 	}
 }
 
+func TestClientRecordReannounceParallel(t *testing.T) {
+	for _, transport := range []string{
+		"udp",
+		"tcp",
+	} {
+		t.Run(transport, func(t *testing.T) {
+			l, err := net.Listen("tcp", "localhost:8554")
+			require.NoError(t, err)
+			defer l.Close()
+
+			serverDone := make(chan struct{})
+			defer func() { <-serverDone }()
+			go func() {
+				defer close(serverDone)
+
+				nconn, err := l.Accept()
+				require.NoError(t, err)
+				defer nconn.Close()
+				conn := conn.NewConn(nconn)
+
+				req, err := conn.ReadRequest()
+				require.NoError(t, err)
+				require.Equal(t, base.Options, req.Method)
+
+				err = conn.WriteResponse(&base.Response{
+					StatusCode: base.StatusOK,
+					Header: base.Header{
+						"Public": base.HeaderValue{strings.Join([]string{
+							string(base.Announce),
+							string(base.Setup),
+							string(base.Record),
+							string(base.Pause),
+						}, ", ")},
+					},
+				})
+				require.NoError(t, err)
+
+				req, err = conn.ReadRequest()
+				require.NoError(t, err)
+				require.Equal(t, base.Announce, req.Method)
+
+				err = conn.WriteResponse(&base.Response{
+					StatusCode: base.StatusOK,
+				})
+				require.NoError(t, err)
+
+				req, err = conn.ReadRequest()
+				require.NoError(t, err)
+				require.Equal(t, base.Setup, req.Method)
+
+				var inTH headers.Transport
+				err = inTH.Unmarshal(req.Header["Transport"])
+				require.NoError(t, err)
+
+				th := headers.Transport{
+					Delivery: func() *headers.TransportDelivery {
+						v := headers.TransportDeliveryUnicast
+						return &v
+					}(),
+				}
+
+				if transport == "udp" {
+					th.Protocol = headers.TransportProtocolUDP
+					th.ServerPorts = &[2]int{34556, 34557}
+					th.ClientPorts = inTH.ClientPorts
+				} else {
+					th.Protocol = headers.TransportProtocolTCP
+					th.InterleavedIDs = inTH.InterleavedIDs
+				}
+
+				err = conn.WriteResponse(&base.Response{
+					StatusCode: base.StatusOK,
+					Header: base.Header{
+						"Transport": th.Marshal(),
+					},
+				})
+				require.NoError(t, err)
+
+				for _, method := range []base.Method{
+					base.Record,
+					base.Pause,
+					base.Announce,
+					base.Record,
+					base.Teardown,
+				} {
+					req, err = conn.ReadRequestIgnoreFrames()
+					require.NoError(t, err)
+					require.Equal(t, method, req.Method)
+
+					err = conn.WriteResponse(&base.Response{
+						StatusCode: base.StatusOK,
+					})
+					require.NoError(t, err)
+				}
+			}()
+
+			c := Client{
+				Transport: func() *Transport {
+					if transport == "udp" {
+						v := TransportUDP
+						return &v
+					}
+					v := TransportTCP
+					return &v
+				}(),
+			}
+
+			medi := testH264Media
+			medias := media.Medias{medi}
+
+			err = record(&c, "rtsp://localhost:8554/teststream", medias, nil)
+			require.NoError(t, err)
+
+			writerTerminate := make(chan struct{})
+			writerDone := make(chan struct{})
+			go func() {
+				defer close(writerDone)
+
+				t := time.NewTicker(1 * time.Millisecond)
+				defer t.Stop()
+
+				for {
+					select {
+					case <-t.C:
+						c.WritePacketRTP(medi, &testRTPPacket)
+					case <-writerTerminate:
+						return
+					}
+				}
+			}()
+
+			time.Sleep(50 * time.Millisecond)
+
+			_, err = c.Pause()
+			require.NoError(t, err)
+
+			medi2 := &media.Media{
+				Type: media.TypeVideo,
+				Formats: []formats.Format{&formats.H264{
+					PayloadTyp:        96,
+					SPS:               []byte{0x05, 0x06, 0x07, 0x08},
+					PPS:               []byte{0x01, 0x02, 0x03, 0x04},
+					PacketizationMode: 1,
+				}},
+			}
+
+			_, err = c.Announce(mustParseURL("rtsp://localhost:8554/teststream"), media.Medias{medi2})
+			require.NoError(t, err)
+
+			_, err = c.Record()
+			require.NoError(t, err)
+
+			time.Sleep(50 * time.Millisecond)
+
+			close(writerTerminate)
+			<-writerDone
+
+			c.Close()
+		})
+	}
+}
+
+func TestClientRecordPauseResumeAnnounce(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer l.Close()
+
+	serverDone := make(chan struct{})
+	defer func() { <-serverDone }()
+	go func() {
+		defer close(serverDone)
+
+		nconn, err := l.Accept()
+		require.NoError(t, err)
+		defer nconn.Close()
+		conn := conn.NewConn(nconn)
+
+		req, err := conn.ReadRequest()
+		require.NoError(t, err)
+		require.Equal(t, base.Options, req.Method)
+
+		err = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+			Header: base.Header{
+				"Public": base.HeaderValue{strings.Join([]string{
+					string(base.Announce),
+					string(base.Setup),
+					string(base.Record),
+					string(base.Pause),
+				}, ", ")},
+			},
+		})
+		require.NoError(t, err)
+
+		req, err = conn.ReadRequest()
+		require.NoError(t, err)
+		require.Equal(t, base.Announce, req.Method)
+
+		err = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+		})
+		require.NoError(t, err)
+
+		req, err = conn.ReadRequest()
+		require.NoError(t, err)
+		require.Equal(t, base.Setup, req.Method)
+
+		var inTH headers.Transport
+		err = inTH.Unmarshal(req.Header["Transport"])
+		require.NoError(t, err)
+
+		th := headers.Transport{
+			Delivery: func() *headers.TransportDelivery {
+				v := headers.TransportDeliveryUnicast
+				return &v
+			}(),
+			Protocol:       headers.TransportProtocolTCP,
+			InterleavedIDs: inTH.InterleavedIDs,
+		}
+
+		err = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+			Header: base.Header{
+				"Transport": th.Marshal(),
+				"Session":   base.HeaderValue{"ABCDE"},
+			},
+		})
+		require.NoError(t, err)
+
+		req, err = conn.ReadRequest()
+		require.NoError(t, err)
+		require.Equal(t, base.Record, req.Method)
+		_, ok := req.Header["Range"]
+		require.Equal(t, false, ok)
+		_, ok = req.Header["RTP-Info"]
+		require.Equal(t, false, ok)
+
+		err = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+		})
+		require.NoError(t, err)
+
+		req, err = conn.ReadRequestIgnoreFrames()
+		require.NoError(t, err)
+		require.Equal(t, base.Pause, req.Method)
+
+		err = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+		})
+		require.NoError(t, err)
+
+		req, err = conn.ReadRequest()
+		require.NoError(t, err)
+		require.Equal(t, base.Announce, req.Method)
+		require.Equal(t, base.HeaderValue{"ABCDE"}, req.Header["Session"])
+
+		var desc sdp.SessionDescription
+		err = desc.Unmarshal(req.Body)
+		require.NoError(t, err)
+
+		var medias media.Medias
+		err = medias.Unmarshal(desc.MediaDescriptions)
+		require.NoError(t, err)
+		require.Equal(t, []byte{0x05, 0x06, 0x07, 0x08}, medias[0].Formats[0].(*formats.H264).SPS)
+
+		err = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+		})
+		require.NoError(t, err)
+
+		req, err = conn.ReadRequest()
+		require.NoError(t, err)
+		require.Equal(t, base.Record, req.Method)
+
+		var ra headers.Range
+		err = ra.Unmarshal(req.Header["Range"])
+		require.NoError(t, err)
+		require.NotEqual(t, time.Duration(0), ra.Value.(*headers.RangeNPT).Start)
+
+		var ri headers.RTPInfo
+		err = ri.Unmarshal(req.Header["RTP-Info"])
+		require.NoError(t, err)
+		require.Equal(t, headers.RTPInfo{{
+			URL: "rtsp://localhost:8554/teststream/" + medias[0].Control,
+			SequenceNumber: func() *uint16 {
+				v := uint16(123)
+				return &v
+			}(),
+			Timestamp: func() *uint32 {
+				v := uint32(456789)
+				return &v
+			}(),
+		}}, ri)
+
+		err = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+		})
+		require.NoError(t, err)
+
+		req, err = conn.ReadRequestIgnoreFrames()
+		require.NoError(t, err)
+		require.Equal(t, base.Teardown, req.Method)
+
+		err = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+		})
+		require.NoError(t, err)
+	}()
+
+	c := Client{
+		Transport: func() *Transport {
+			v := TransportTCP
+			return &v
+		}(),
+	}
+
+	medi := &media.Media{
+		Type: media.TypeVideo,
+		Formats: []formats.Format{&formats.H264{
+			PayloadTyp:        96,
+			SPS:               []byte{0x01, 0x02, 0x03, 0x04},
+			PPS:               []byte{0x01, 0x02, 0x03, 0x04},
+			PacketizationMode: 1,
+		}},
+	}
+
+	err = record(&c, "rtsp://localhost:8554/teststream", media.Medias{medi}, nil)
+	require.NoError(t, err)
+	defer c.Close()
+
+	err = c.WritePacketRTP(medi, &rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			PayloadType:    96,
+			SequenceNumber: 946,
+			Timestamp:      54352,
+			SSRC:           0x38F27A2F,
+		},
+		Payload: []byte{0x05, 0x02, 0x03, 0x04}, // IDR
+	})
+	require.NoError(t, err)
+
+	// make sure that the recorded duration is not zero
+	time.Sleep(10 * time.Millisecond)
+
+	_, err = c.Pause()
+	require.NoError(t, err)
+
+	medi2 := &media.Media{
+		Type: media.TypeVideo,
+		Formats: []formats.Format{&formats.H264{
+			PayloadTyp:        96,
+			SPS:               []byte{0x05, 0x06, 0x07, 0x08},
+			PPS:               []byte{0x01, 0x02, 0x03, 0x04},
+			PacketizationMode: 1,
+		}},
+	}
+
+	forma := &formats.Generic{
+		PayloadTyp: 96,
+		RTPMa:      "private/48000",
+	}
+	err = forma.Init()
+	require.NoError(t, err)
+
+	_, err = c.Announce(mustParseURL("rtsp://localhost:8554/teststream"), media.Medias{{
+		Type:    media.TypeVideo,
+		Formats: []formats.Format{forma},
+	}})
+	require.EqualError(t, err, "clock rate of payload type 96 can't be changed")
+
+	_, err = c.Announce(mustParseURL("rtsp://localhost:8554/teststream"), media.Medias{medi2})
+	require.NoError(t, err)
+
+	_, err = c.RecordWithRTPInfo(map[*media.Media]*headers.RTPInfoEntry{
+		medi: {
+			SequenceNumber: func() *uint16 {
+				v := uint16(123)
+				return &v
+			}(),
+			Timestamp: func() *uint32 {
+				v := uint32(456789)
+				return &v
+			}(),
+		},
+	})
+	require.NoError(t, err)
+
+	// packets are still written with the media passed to Setup()
+	err = c.WritePacketRTP(medi, &testRTPPacket)
+	require.NoError(t, err)
+}
+
 func TestClientRecordAutomaticProtocol(t *testing.T) {
 	l, err := net.Listen("tcp", "localhost:8554")
 	require.NoError(t, err)
@@ -20,7 +20,7 @@ func randInRange(max int) int {
 type clientUDPListener struct {
 	anyPortEnable bool
 	writeTimeout  time.Duration
-	pc            *net.UDPConn
+	pc            net.PacketConn
 	cm            *clientMedia
 	isRTP         bool
 
@@ -83,7 +83,7 @@ func newClientUDPListener(
 	cm *clientMedia,
 	isRTP bool,
 ) (*clientUDPListener, error) {
-	var pc *net.UDPConn
+	var pc net.PacketConn
 	if multicast {
 		host, port, err := net.SplitHostPort(address)
 		if err != nil {
@@ -114,16 +114,16 @@ func newClientUDPListener(
 			}
 		}
 
-		pc = tmp.(*net.UDPConn)
+		pc = tmp
 	} else {
 		tmp, err := listenPacket(restrictNetwork("udp", address))
 		if err != nil {
 			return nil, err
 		}
-		pc = tmp.(*net.UDPConn)
+		pc = tmp
 	}
 
-	err := pc.SetReadBuffer(udpKernelReadBufferSize)
+	err := setReadBuffer(pc, udpKernelReadBufferSize)
 	if err != nil {
 		return nil, err
 	}
@@ -149,7 +149,7 @@ func (u *clientUDPListener) close() {
 }
 
 func (u *clientUDPListener) port() int {
-	return u.pc.LocalAddr().(*net.UDPAddr).Port
+	return udpAddr(u.pc.LocalAddr()).Port
 }
 
 func (u *clientUDPListener) start(forPlay bool) {
@@ -181,7 +181,7 @@ func (u *clientUDPListener) runReader(forPlay bool) {
 			return
 		}
 
-		uaddr := addr.(*net.UDPAddr)
+		uaddr := udpAddr(addr)
 
 		if !u.readIP.Equal(uaddr.IP) {
 			continue
@@ -0,0 +1,53 @@
+package gortsplib
+
+import (
+	"net"
+	"strconv"
+)
+
+func splitAddr(addr net.Addr) (net.IP, int) {
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return nil, 0
+	}
+
+	tmp, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, 0
+	}
+
+	return net.ParseIP(host), int(tmp)
+}
+
+// tcpAddr converts the address of a connection into a *net.TCPAddr.
+// Connections provided through DialContext or Listen (in-memory pipes, tunnels, QUIC streams)
+// are not required to use *net.TCPAddr; in this case, the address is parsed from its
+// host:port form, and IP is nil when the address doesn't contain an IP.
+func tcpAddr(addr net.Addr) *net.TCPAddr {
+	if taddr, ok := addr.(*net.TCPAddr); ok {
+		return taddr
+	}
+
+	ip, port := splitAddr(addr)
+	return &net.TCPAddr{IP: ip, Port: port}
+}
+
+// udpAddr converts the address of a packet connection into a *net.UDPAddr.
+// Packet connections provided through ListenPacket are not required to use *net.UDPAddr;
+// in this case, the address is parsed from its host:port form.
+func udpAddr(addr net.Addr) *net.UDPAddr {
+	if uaddr, ok := addr.(*net.UDPAddr); ok {
+		return uaddr
+	}
+
+	ip, port := splitAddr(addr)
+	return &net.UDPAddr{IP: ip, Port: port}
+}
+
+// setReadBuffer sets the kernel read buffer of a packet connection, if supported.
+func setReadBuffer(pc net.PacketConn, size int) error {
+	if rb, ok := pc.(interface{ SetReadBuffer(int) error }); ok {
+		return rb.SetReadBuffer(size)
+	}
+	return nil
+}
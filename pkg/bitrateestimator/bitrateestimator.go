@@ -0,0 +1,315 @@
+// Package bitrateestimator contains a utility to estimate the available bitrate of a sender.
+package bitrateestimator
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+)
+
+const (
+	// loss fractions that separate the increase, hold and decrease states.
+	lossLowThreshold  = 0.02
+	lossHighThreshold = 0.10
+
+	// bitrate multiplier applied when the path is not congested.
+	increaseFactor = 1.08
+
+	// bitrate multiplier applied when delay is increasing.
+	delayDecreaseFactor = 0.85
+
+	// the estimate can't grow beyond the measured send rate multiplied by this factor.
+	sendRateHeadroom = 1.5
+
+	// queuing delay growth inside a single feedback that is considered congestion.
+	delayGrowthThreshold = 20 * time.Millisecond
+
+	// minimum jitter increase that is considered congestion.
+	jitterGrowthThreshold = 30 * time.Millisecond
+
+	// number of sent packets whose send time is kept, for transport-wide feedback.
+	sentPacketsBufferSize = 1 << 10
+)
+
+type sentPacket struct {
+	valid          bool
+	sequenceNumber uint16
+	ntp            time.Time
+}
+
+// BitrateEstimator is a utility to estimate the available bitrate of a sender.
+// It ingests RTCP receiver reports (loss and jitter) and, optionally,
+// transport-wide congestion control feedback (draft-holmer-rmcat-transport-wide-cc-extensions).
+type BitrateEstimator struct {
+	clockRate              float64
+	minBitrate             float64
+	maxBitrate             float64
+	transportCCExtensionID uint8
+	onCongestion           func(int)
+	mutex                  sync.Mutex
+
+	bitrate float64
+
+	// data from RTP packets
+	initialized bool
+	lastSSRC    uint32
+	windowBytes uint64
+	windowStart time.Time
+	windowEnd   time.Time
+	sentPackets [sentPacketsBufferSize]sentPacket
+
+	// data from RTCP packets
+	jitterInit    bool
+	jitterMinimum float64
+}
+
+// New allocates a BitrateEstimator.
+// transportCCExtensionID is the ID of the header extension that contains the
+// transport-wide sequence number; it can be zero to disable transport-wide feedback.
+// onCongestion is called with the new estimate every time congestion is detected.
+func New(
+	clockRate int,
+	initialBitrate int,
+	minBitrate int,
+	maxBitrate int,
+	transportCCExtensionID uint8,
+	onCongestion func(int),
+) *BitrateEstimator {
+	return &BitrateEstimator{
+		clockRate:              float64(clockRate),
+		minBitrate:             float64(minBitrate),
+		maxBitrate:             float64(maxBitrate),
+		transportCCExtensionID: transportCCExtensionID,
+		onCongestion:           onCongestion,
+		bitrate:                float64(initialBitrate),
+	}
+}
+
+// Bitrate returns the estimated available bitrate, in bits per second.
+func (be *BitrateEstimator) Bitrate() int {
+	be.mutex.Lock()
+	defer be.mutex.Unlock()
+	return int(be.bitrate)
+}
+
+// ProcessPacket extracts the needed data from sent RTP packets.
+func (be *BitrateEstimator) ProcessPacket(pkt *rtp.Packet, ntp time.Time) {
+	be.mutex.Lock()
+	defer be.mutex.Unlock()
+
+	be.initialized = true
+	be.lastSSRC = pkt.SSRC
+
+	if be.windowBytes == 0 {
+		be.windowStart = ntp
+	}
+	be.windowBytes += uint64(pkt.MarshalSize())
+	be.windowEnd = ntp
+
+	if be.transportCCExtensionID != 0 {
+		if buf := pkt.GetExtension(be.transportCCExtensionID); buf != nil {
+			var ext rtp.TransportCCExtension
+			if err := ext.Unmarshal(buf); err == nil {
+				be.sentPackets[ext.TransportSequence%sentPacketsBufferSize] = sentPacket{
+					valid:          true,
+					sequenceNumber: ext.TransportSequence,
+					ntp:            ntp,
+				}
+			}
+		}
+	}
+}
+
+// ProcessPacketRTCP extracts the needed data from received RTCP packets.
+// It handles receiver reports, sender reports and transport-wide feedback;
+// other packets are ignored.
+func (be *BitrateEstimator) ProcessPacketRTCP(pkt rtcp.Packet) {
+	var congested bool
+	var bitrate int
+
+	func() {
+		be.mutex.Lock()
+		defer be.mutex.Unlock()
+
+		switch pkt := pkt.(type) {
+		case *rtcp.ReceiverReport:
+			congested = be.processReceptionReports(pkt.Reports)
+
+		case *rtcp.SenderReport:
+			congested = be.processReceptionReports(pkt.Reports)
+
+		case *rtcp.TransportLayerCC:
+			congested = be.processTransportLayerCC(pkt)
+
+		default:
+			return
+		}
+
+		bitrate = int(be.bitrate)
+	}()
+
+	if congested && be.onCongestion != nil {
+		be.onCongestion(bitrate)
+	}
+}
+
+func (be *BitrateEstimator) processReceptionReports(reports []rtcp.ReceptionReport) bool {
+	for _, report := range reports {
+		if be.initialized && report.SSRC != be.lastSSRC {
+			continue
+		}
+
+		loss := float64(report.FractionLost) / 256
+		return be.update(loss, be.jitterIncreasing(float64(report.Jitter)))
+	}
+
+	return false
+}
+
+func (be *BitrateEstimator) jitterIncreasing(jitter float64) bool {
+	if be.clockRate == 0 {
+		return false
+	}
+
+	jitterSecs := jitter / be.clockRate
+
+	if !be.jitterInit || jitterSecs < be.jitterMinimum {
+		be.jitterInit = true
+		be.jitterMinimum = jitterSecs
+		return false
+	}
+
+	growth := time.Duration((jitterSecs - be.jitterMinimum) * float64(time.Second))
+
+	// let the minimum slowly follow the current value,
+	// in order to adapt to paths with a higher baseline jitter
+	be.jitterMinimum += (jitterSecs - be.jitterMinimum) / 64
+
+	return growth > jitterGrowthThreshold && jitterSecs > 2*be.jitterMinimum
+}
+
+func (be *BitrateEstimator) processTransportLayerCC(fb *rtcp.TransportLayerCC) bool {
+	if be.transportCCExtensionID == 0 {
+		return false
+	}
+
+	// reference time is expressed in multiples of 64ms
+	arrival := time.Duration(fb.ReferenceTime) * 64 * time.Millisecond
+
+	seqNum := fb.BaseSequenceNumber
+	remaining := int(fb.PacketStatusCount)
+	deltas := fb.RecvDeltas
+	received := 0
+	lost := 0
+
+	var firstArrival, lastArrival time.Duration
+	var firstSend, lastSend time.Time
+	firstFound := false
+
+	processSymbol := func(symbol uint16) {
+		if remaining == 0 {
+			return
+		}
+		remaining--
+
+		switch symbol {
+		case rtcp.TypeTCCPacketReceivedSmallDelta, rtcp.TypeTCCPacketReceivedLargeDelta:
+			received++
+
+			if len(deltas) > 0 {
+				arrival += time.Duration(deltas[0].Delta) * time.Microsecond
+				deltas = deltas[1:]
+
+				sent := be.sentPackets[seqNum%sentPacketsBufferSize]
+				if sent.valid && sent.sequenceNumber == seqNum {
+					if !firstFound {
+						firstFound = true
+						firstArrival = arrival
+						firstSend = sent.ntp
+					}
+					lastArrival = arrival
+					lastSend = sent.ntp
+				}
+			}
+
+		case rtcp.TypeTCCPacketNotReceived:
+			lost++
+		}
+
+		seqNum++
+	}
+
+	for _, chunk := range fb.PacketChunks {
+		switch chunk := chunk.(type) {
+		case *rtcp.RunLengthChunk:
+			for i := uint16(0); i < chunk.RunLength; i++ {
+				processSymbol(chunk.PacketStatusSymbol)
+			}
+
+		case *rtcp.StatusVectorChunk:
+			for _, symbol := range chunk.SymbolList {
+				// one-bit symbols only tell whether the packet has been received
+				if chunk.SymbolSize == rtcp.TypeTCCSymbolSizeOneBit && symbol != 0 {
+					symbol = rtcp.TypeTCCPacketReceivedSmallDelta
+				}
+				processSymbol(symbol)
+			}
+		}
+	}
+
+	if (received + lost) == 0 {
+		return false
+	}
+
+	loss := float64(lost) / float64(received+lost)
+
+	// growth of the queuing delay between the first and the last received packet
+	delayGrowth := (lastArrival - firstArrival) - lastSend.Sub(firstSend)
+
+	return be.update(loss, firstFound && delayGrowth > delayGrowthThreshold)
+}
+
+// update updates the estimate and returns whether congestion has been detected.
+func (be *BitrateEstimator) update(loss float64, delayIncreasing bool) bool {
+	sendRate := float64(0)
+	if elapsed := be.windowEnd.Sub(be.windowStart); elapsed > 0 {
+		sendRate = float64(be.windowBytes*8) / elapsed.Seconds()
+	}
+	be.windowBytes = 0
+
+	congested := false
+
+	switch {
+	case loss > lossHighThreshold:
+		be.bitrate *= 1 - 0.5*loss
+		congested = true
+
+	case delayIncreasing:
+		be.bitrate *= delayDecreaseFactor
+		congested = true
+
+	case loss < lossLowThreshold:
+		increased := be.bitrate * increaseFactor
+
+		// do not increase the estimate when the sender is not using it
+		if sendRate > 0 && increased > sendRate*sendRateHeadroom {
+			increased = be.bitrate
+			if sendRate*sendRateHeadroom > increased {
+				increased = sendRate * sendRateHeadroom
+			}
+		}
+
+		be.bitrate = increased
+	}
+
+	if be.maxBitrate > 0 && be.bitrate > be.maxBitrate {
+		be.bitrate = be.maxBitrate
+	}
+	if be.bitrate < be.minBitrate {
+		be.bitrate = be.minBitrate
+	}
+
+	return congested
+}
@@ -0,0 +1,170 @@
+package bitrateestimator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitrateEstimatorReceiverReport(t *testing.T) {
+	var congestionBitrates []int
+
+	be := New(90000, 1000000, 100000, 2000000, 0, func(bitrate int) {
+		congestionBitrates = append(congestionBitrates, bitrate)
+	})
+
+	ts := time.Date(2008, 5, 20, 22, 15, 20, 0, time.UTC)
+	for i := 0; i < 100; i++ {
+		be.ProcessPacket(&rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    96,
+				SequenceNumber: uint16(946 + i),
+				SSRC:           0xba9da416,
+			},
+			Payload: make([]byte, 1488),
+		}, ts.Add(time.Duration(i)*10*time.Millisecond))
+	}
+
+	// no loss: the estimate is increased
+	be.ProcessPacketRTCP(&rtcp.ReceiverReport{
+		SSRC: 0x65f83afb,
+		Reports: []rtcp.ReceptionReport{{
+			SSRC: 0xba9da416,
+		}},
+	})
+	require.Equal(t, 1080000, be.Bitrate())
+	require.Equal(t, []int(nil), congestionBitrates)
+
+	// reports of other SSRCs are ignored
+	be.ProcessPacketRTCP(&rtcp.ReceiverReport{
+		SSRC: 0x65f83afb,
+		Reports: []rtcp.ReceptionReport{{
+			SSRC:         0x12345678,
+			FractionLost: 128,
+		}},
+	})
+	require.Equal(t, 1080000, be.Bitrate())
+
+	// moderate loss: the estimate is kept
+	be.ProcessPacketRTCP(&rtcp.ReceiverReport{
+		SSRC: 0x65f83afb,
+		Reports: []rtcp.ReceptionReport{{
+			SSRC:         0xba9da416,
+			FractionLost: 12,
+		}},
+	})
+	require.Equal(t, 1080000, be.Bitrate())
+
+	// high loss: the estimate is decreased
+	be.ProcessPacketRTCP(&rtcp.ReceiverReport{
+		SSRC: 0x65f83afb,
+		Reports: []rtcp.ReceptionReport{{
+			SSRC:         0xba9da416,
+			FractionLost: 128,
+		}},
+	})
+	require.Equal(t, 810000, be.Bitrate())
+	require.Equal(t, []int{810000}, congestionBitrates)
+
+	// the estimate never goes below the minimum
+	for i := 0; i < 10; i++ {
+		be.ProcessPacketRTCP(&rtcp.ReceiverReport{
+			SSRC: 0x65f83afb,
+			Reports: []rtcp.ReceptionReport{{
+				SSRC:         0xba9da416,
+				FractionLost: 255,
+			}},
+		})
+	}
+	require.Equal(t, 100000, be.Bitrate())
+}
+
+func TestBitrateEstimatorJitter(t *testing.T) {
+	congested := false
+
+	be := New(90000, 1000000, 100000, 2000000, 0, func(bitrate int) {
+		congested = true
+	})
+
+	be.ProcessPacketRTCP(&rtcp.ReceiverReport{
+		Reports: []rtcp.ReceptionReport{{
+			Jitter: 90, // 1ms
+		}},
+	})
+	require.Equal(t, false, congested)
+
+	be.ProcessPacketRTCP(&rtcp.ReceiverReport{
+		Reports: []rtcp.ReceptionReport{{
+			Jitter: 9000, // 100ms
+		}},
+	})
+	require.Equal(t, true, congested)
+	require.Equal(t, 918000, be.Bitrate())
+}
+
+func TestBitrateEstimatorTransportCC(t *testing.T) {
+	var congestionBitrates []int
+
+	be := New(90000, 1000000, 100000, 2000000, 5, func(bitrate int) {
+		congestionBitrates = append(congestionBitrates, bitrate)
+	})
+
+	ts := time.Date(2008, 5, 20, 22, 15, 20, 0, time.UTC)
+	for i := 0; i < 8; i++ {
+		pkt := &rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    96,
+				SequenceNumber: uint16(946 + i),
+				SSRC:           0xba9da416,
+			},
+			Payload: make([]byte, 1000),
+		}
+		buf, _ := rtp.TransportCCExtension{TransportSequence: uint16(65533 + i)}.Marshal()
+		err := pkt.SetExtension(5, buf)
+		require.NoError(t, err)
+		be.ProcessPacket(pkt, ts.Add(time.Duration(i)*time.Millisecond))
+	}
+
+	// no loss, constant delay
+	be.ProcessPacketRTCP(&rtcp.TransportLayerCC{
+		BaseSequenceNumber: 65533,
+		PacketStatusCount:  4,
+		PacketChunks: []rtcp.PacketStatusChunk{
+			&rtcp.RunLengthChunk{
+				PacketStatusSymbol: rtcp.TypeTCCPacketReceivedSmallDelta,
+				RunLength:          4,
+			},
+		},
+		RecvDeltas: []*rtcp.RecvDelta{
+			{Type: rtcp.TypeTCCPacketReceivedSmallDelta, Delta: 1000},
+			{Type: rtcp.TypeTCCPacketReceivedSmallDelta, Delta: 1000},
+			{Type: rtcp.TypeTCCPacketReceivedSmallDelta, Delta: 1000},
+			{Type: rtcp.TypeTCCPacketReceivedSmallDelta, Delta: 1000},
+		},
+	})
+	require.Equal(t, []int(nil), congestionBitrates)
+
+	// no loss, increasing delay
+	be.ProcessPacketRTCP(&rtcp.TransportLayerCC{
+		BaseSequenceNumber: 1,
+		PacketStatusCount:  4,
+		PacketChunks: []rtcp.PacketStatusChunk{
+			&rtcp.StatusVectorChunk{
+				SymbolSize: rtcp.TypeTCCSymbolSizeOneBit,
+				SymbolList: []uint16{1, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			},
+		},
+		RecvDeltas: []*rtcp.RecvDelta{
+			{Type: rtcp.TypeTCCPacketReceivedSmallDelta, Delta: 1000},
+			{Type: rtcp.TypeTCCPacketReceivedSmallDelta, Delta: 10000},
+			{Type: rtcp.TypeTCCPacketReceivedSmallDelta, Delta: 10000},
+			{Type: rtcp.TypeTCCPacketReceivedSmallDelta, Delta: 10000},
+		},
+	})
+	require.Equal(t, 1, len(congestionBitrates))
+}
@@ -496,6 +496,37 @@ var casesFormat = []struct {
 			"sprop-stereo": "1",
 		},
 	},
+	{
+		"audio opus fec dtx",
+		"audio",
+		111,
+		"opus/48000/2",
+		map[string]string{
+			"sprop-stereo":      "0",
+			"stereo":            "1",
+			"maxaveragebitrate": "64000",
+			"useinbandfec":      "1",
+			"usedtx":            "1",
+		},
+		&Opus{
+			PayloadTyp: 111,
+			Stereo:     true,
+			MaxAverageBitrate: func() *int {
+				v := 64000
+				return &v
+			}(),
+			UseInbandFEC: true,
+			UseDTX:       true,
+		},
+		"opus/48000/2",
+		map[string]string{
+			"sprop-stereo":      "0",
+			"stereo":            "1",
+			"maxaveragebitrate": "64000",
+			"useinbandfec":      "1",
+			"usedtx":            "1",
+		},
+	},
 	{
 		"video jpeg",
 		"video",
@@ -961,6 +992,23 @@ func TestUnmarshalAV1Errors(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestUnmarshalOpusErrors(t *testing.T) {
+	_, err := Unmarshal("audio", 111, "opus/48000/2", map[string]string{
+		"maxaveragebitrate": "aaa",
+	})
+	require.Error(t, err)
+
+	_, err = Unmarshal("audio", 111, "opus/48000/2", map[string]string{
+		"maxaveragebitrate": "1",
+	})
+	require.Error(t, err)
+
+	_, err = Unmarshal("audio", 111, "opus/48000/2", map[string]string{
+		"maxaveragebitrate": "510001",
+	})
+	require.Error(t, err)
+}
+
 func FuzzUnmarshalH264(f *testing.F) {
 	f.Fuzz(func(t *testing.T, sps string, pktMode string) {
 		Unmarshal("video", 96, "H264/90000", map[string]string{
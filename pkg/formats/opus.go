@@ -14,7 +14,21 @@ import (
 // Specification: https://datatracker.ietf.org/doc/html/rfc7587
 type Opus struct {
 	PayloadTyp uint8
-	IsStereo   bool
+
+	// the sender is likely to produce stereo audio (sprop-stereo).
+	IsStereo bool
+
+	// the receiver prefers receiving stereo audio (stereo).
+	Stereo bool
+
+	// maximum average bitrate that the receiver is able to handle, between 6000 and 510000 (maxaveragebitrate).
+	MaxAverageBitrate *int
+
+	// the decoder has the capability to take advantage of in-band FEC (useinbandfec).
+	UseInbandFEC bool
+
+	// the decoder prefers the use of DTX (usedtx).
+	UseDTX bool
 }
 
 func (f *Opus) unmarshal(payloadType uint8, clock string, codec string, rtpmap string, fmtp map[string]string) error {
@@ -36,8 +50,27 @@ func (f *Opus) unmarshal(payloadType uint8, clock string, codec string, rtpmap s
 	}
 
 	for key, val := range fmtp {
-		if key == "sprop-stereo" {
+		switch key {
+		case "sprop-stereo":
 			f.IsStereo = (val == "1")
+
+		case "stereo":
+			f.Stereo = (val == "1")
+
+		case "maxaveragebitrate":
+			n, err := strconv.ParseUint(val, 10, 31)
+			if err != nil || n < 6000 || n > 510000 {
+				return fmt.Errorf("invalid maxaveragebitrate: %v", val)
+			}
+
+			v2 := int(n)
+			f.MaxAverageBitrate = &v2
+
+		case "useinbandfec":
+			f.UseInbandFEC = (val == "1")
+
+		case "usedtx":
+			f.UseDTX = (val == "1")
 		}
 	}
 
@@ -78,6 +111,23 @@ func (f *Opus) FMTP() map[string]string {
 			return "0"
 		}(),
 	}
+
+	if f.Stereo {
+		fmtp["stereo"] = "1"
+	}
+
+	if f.MaxAverageBitrate != nil {
+		fmtp["maxaveragebitrate"] = strconv.FormatInt(int64(*f.MaxAverageBitrate), 10)
+	}
+
+	if f.UseInbandFEC {
+		fmtp["useinbandfec"] = "1"
+	}
+
+	if f.UseDTX {
+		fmtp["usedtx"] = "1"
+	}
+
 	return fmtp
 }
 
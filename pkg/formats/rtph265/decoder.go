@@ -3,6 +3,7 @@ package rtph265
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/pion/rtp"
@@ -30,19 +31,42 @@ func joinFragments(fragments [][]byte, size int) []byte {
 	return ret
 }
 
+// donSorter sorts NALUs by decoding order number, taking wrap-around into account.
+type donSorter struct {
+	nalus [][]byte
+	dons  []uint16
+}
+
+func (s *donSorter) Len() int {
+	return len(s.nalus)
+}
+
+func (s *donSorter) Less(i, j int) bool {
+	return int16(s.dons[i]-s.dons[j]) < 0
+}
+
+func (s *donSorter) Swap(i, j int) {
+	s.nalus[i], s.nalus[j] = s.nalus[j], s.nalus[i]
+	s.dons[i], s.dons[j] = s.dons[j], s.dons[i]
+}
+
 // Decoder is a RTP/H265 decoder.
 // Specification: https://datatracker.ietf.org/doc/html/rfc7798
 type Decoder struct {
 	// indicates that NALUs have an additional field that specifies the decoding order.
+	// Only DecodeUntilMarker() uses this order, and reordering is limited to
+	// NALUs of the same access unit.
 	MaxDONDiff int
 
 	timeDecoder         *rtptime.Decoder
 	firstPacketReceived bool
 	fragmentsSize       int
 	fragments           [][]byte
+	fragmentsDON        uint16
 
 	// for DecodeUntilMarker()
 	frameBuffer    [][]byte
+	frameBufferDON []uint16
 	frameBufferLen int
 }
 
@@ -52,28 +76,50 @@ func (d *Decoder) Init() {
 }
 
 // Decode decodes NALUs from a RTP packet.
+// NALUs are returned in transmission order, even when MaxDONDiff is not zero.
 func (d *Decoder) Decode(pkt *rtp.Packet) ([][]byte, time.Duration, error) {
-	if d.MaxDONDiff != 0 {
-		return nil, 0, fmt.Errorf("MaxDONDiff != 0 is not supported (yet)")
-	}
+	nalus, _, pts, err := d.decode(pkt)
+	return nalus, pts, err
+}
 
+func (d *Decoder) decode(pkt *rtp.Packet) ([][]byte, []uint16, time.Duration, error) {
 	if len(pkt.Payload) < 2 {
 		d.fragments = d.fragments[:0] // discard pending fragments
-		return nil, 0, fmt.Errorf("payload is too short")
+		return nil, nil, 0, fmt.Errorf("payload is too short")
 	}
 
 	typ := h265.NALUType((pkt.Payload[0] >> 1) & 0b111111)
 	var nalus [][]byte
+	var dons []uint16
 
 	switch typ {
 	case h265.NALUType_AggregationUnit:
 		d.fragments = d.fragments[:0] // discard pending fragments
 
 		payload := pkt.Payload[2:]
+		var don uint16
 
 		for len(payload) > 0 {
+			if d.MaxDONDiff != 0 {
+				if nalus == nil {
+					if len(payload) < 2 {
+						return nil, nil, 0, fmt.Errorf("invalid aggregation unit (invalid DONL)")
+					}
+
+					don = uint16(payload[0])<<8 | uint16(payload[1])
+					payload = payload[2:]
+				} else {
+					if len(payload) < 1 {
+						return nil, nil, 0, fmt.Errorf("invalid aggregation unit (invalid DOND)")
+					}
+
+					don += uint16(payload[0]) + 1
+					payload = payload[1:]
+				}
+			}
+
 			if len(payload) < 2 {
-				return nil, 0, fmt.Errorf("invalid aggregation unit (invalid size)")
+				return nil, nil, 0, fmt.Errorf("invalid aggregation unit (invalid size)")
 			}
 
 			size := uint16(payload[0])<<8 | uint16(payload[1])
@@ -84,15 +130,16 @@ func (d *Decoder) Decode(pkt *rtp.Packet) ([][]byte, time.Duration, error) {
 			}
 
 			if int(size) > len(payload) {
-				return nil, 0, fmt.Errorf("invalid aggregation unit (invalid size)")
+				return nil, nil, 0, fmt.Errorf("invalid aggregation unit (invalid size)")
 			}
 
 			nalus = append(nalus, payload[:size])
+			dons = append(dons, don)
 			payload = payload[size:]
 		}
 
 		if nalus == nil {
-			return nil, 0, fmt.Errorf("aggregation unit doesn't contain any NALU")
+			return nil, nil, 0, fmt.Errorf("aggregation unit doesn't contain any NALU")
 		}
 
 		d.firstPacketReceived = true
@@ -100,7 +147,7 @@ func (d *Decoder) Decode(pkt *rtp.Packet) ([][]byte, time.Duration, error) {
 	case h265.NALUType_FragmentationUnit:
 		if len(pkt.Payload) < 3 {
 			d.fragments = d.fragments[:0] // discard pending fragments
-			return nil, 0, fmt.Errorf("payload is too short")
+			return nil, nil, 0, fmt.Errorf("payload is too short")
 		}
 
 		start := pkt.Payload[2] >> 7
@@ -110,61 +157,92 @@ func (d *Decoder) Decode(pkt *rtp.Packet) ([][]byte, time.Duration, error) {
 			d.fragments = d.fragments[:0] // discard pending fragments
 
 			if end != 0 {
-				return nil, 0, fmt.Errorf("invalid fragmentation unit (can't contain both a start and end bit)")
+				return nil, nil, 0, fmt.Errorf("invalid fragmentation unit (can't contain both a start and end bit)")
+			}
+
+			data := pkt.Payload[3:]
+
+			// DONL is present in the first fragment only
+			if d.MaxDONDiff != 0 {
+				if len(data) < 2 {
+					return nil, nil, 0, fmt.Errorf("payload is too short")
+				}
+
+				d.fragmentsDON = uint16(data[0])<<8 | uint16(data[1])
+				data = data[2:]
 			}
 
 			typ := pkt.Payload[2] & 0b111111
 			head := uint16(pkt.Payload[0]&0b10000001)<<8 | uint16(typ)<<9 | uint16(pkt.Payload[1])
-			d.fragmentsSize = len(pkt.Payload[1:])
-			d.fragments = append(d.fragments, []byte{byte(head >> 8), byte(head)}, pkt.Payload[3:])
+			d.fragmentsSize = 2 + len(data)
+			d.fragments = append(d.fragments, []byte{byte(head >> 8), byte(head)}, data)
 			d.firstPacketReceived = true
 
-			return nil, 0, ErrMorePacketsNeeded
+			return nil, nil, 0, ErrMorePacketsNeeded
 		}
 
 		if len(d.fragments) == 0 {
 			if !d.firstPacketReceived {
-				return nil, 0, ErrNonStartingPacketAndNoPrevious
+				return nil, nil, 0, ErrNonStartingPacketAndNoPrevious
 			}
 
-			return nil, 0, fmt.Errorf("invalid fragmentation unit (non-starting)")
+			return nil, nil, 0, fmt.Errorf("invalid fragmentation unit (non-starting)")
 		}
 
 		d.fragmentsSize += len(pkt.Payload[3:])
 		if d.fragmentsSize > h265.MaxNALUSize {
 			d.fragments = d.fragments[:0]
-			return nil, 0, fmt.Errorf("NALU size (%d) is too big, maximum is %d", d.fragmentsSize, h265.MaxNALUSize)
+			return nil, nil, 0, fmt.Errorf("NALU size (%d) is too big, maximum is %d", d.fragmentsSize, h265.MaxNALUSize)
 		}
 
 		d.fragments = append(d.fragments, pkt.Payload[3:])
 
 		if end != 1 {
-			return nil, 0, ErrMorePacketsNeeded
+			return nil, nil, 0, ErrMorePacketsNeeded
 		}
 
 		nalus = [][]byte{joinFragments(d.fragments, d.fragmentsSize)}
+		dons = []uint16{d.fragmentsDON}
 
 		d.fragments = d.fragments[:0]
 
 	case h265.NALUType_PACI:
 		d.fragments = d.fragments[:0] // discard pending fragments
 		d.firstPacketReceived = true
-		return nil, 0, fmt.Errorf("PACI packets are not supported (yet)")
+		return nil, nil, 0, fmt.Errorf("PACI packets are not supported (yet)")
 
 	default:
 		d.fragments = d.fragments[:0] // discard pending fragments
 		d.firstPacketReceived = true
-		nalus = [][]byte{pkt.Payload}
+
+		if d.MaxDONDiff != 0 {
+			if len(pkt.Payload) < 4 {
+				return nil, nil, 0, fmt.Errorf("payload is too short")
+			}
+
+			// remove DONL from the NALU
+			nalu := make([]byte, len(pkt.Payload)-2)
+			copy(nalu, pkt.Payload[:2])
+			copy(nalu[2:], pkt.Payload[4:])
+
+			nalus = [][]byte{nalu}
+			dons = []uint16{uint16(pkt.Payload[2])<<8 | uint16(pkt.Payload[3])}
+		} else {
+			nalus = [][]byte{pkt.Payload}
+			dons = []uint16{0}
+		}
 	}
 
-	return nalus, d.timeDecoder.Decode(pkt.Timestamp), nil
+	return nalus, dons, d.timeDecoder.Decode(pkt.Timestamp), nil
 }
 
 // DecodeUntilMarker decodes NALUs from a RTP packet and puts them in a buffer.
 // When a packet has the marker flag (meaning that all the NALUs with the same PTS have
 // been received), the buffer is returned.
+// When MaxDONDiff is not zero, NALUs are returned in decoding order.
+// NALUs interleaved across different access units are not reordered.
 func (d *Decoder) DecodeUntilMarker(pkt *rtp.Packet) ([][]byte, time.Duration, error) {
-	nalus, pts, err := d.Decode(pkt)
+	nalus, dons, pts, err := d.decode(pkt)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -172,12 +250,14 @@ func (d *Decoder) DecodeUntilMarker(pkt *rtp.Packet) ([][]byte, time.Duration, e
 
 	if (d.frameBufferLen + l) > h265.MaxNALUsPerGroup {
 		d.frameBuffer = nil
+		d.frameBufferDON = nil
 		d.frameBufferLen = 0
 		return nil, 0, fmt.Errorf("NALU count exceeds maximum allowed (%d)",
 			h265.MaxNALUsPerGroup)
 	}
 
 	d.frameBuffer = append(d.frameBuffer, nalus...)
+	d.frameBufferDON = append(d.frameBufferDON, dons...)
 	d.frameBufferLen += l
 
 	if !pkt.Marker {
@@ -186,8 +266,13 @@ func (d *Decoder) DecodeUntilMarker(pkt *rtp.Packet) ([][]byte, time.Duration, e
 
 	ret := d.frameBuffer
 
+	if d.MaxDONDiff != 0 {
+		sort.Stable(&donSorter{nalus: ret, dons: d.frameBufferDON})
+	}
+
 	// do not reuse frameBuffer to avoid race conditions
 	d.frameBuffer = nil
+	d.frameBufferDON = nil
 	d.frameBufferLen = 0
 
 	return ret, pts, nil
@@ -36,6 +36,73 @@ func TestDecode(t *testing.T) {
 	}
 }
 
+func TestDecodeDON(t *testing.T) {
+	for _, ca := range casesDON {
+		t.Run(ca.name, func(t *testing.T) {
+			d := &Decoder{MaxDONDiff: 2}
+			d.Init()
+
+			var nalus [][]byte
+
+			for _, pkt := range ca.pkts {
+				clone := pkt.Clone()
+
+				addNALUs, _, err := d.Decode(pkt)
+				if err == ErrMorePacketsNeeded {
+					continue
+				}
+
+				require.NoError(t, err)
+				nalus = append(nalus, addNALUs...)
+
+				// test input integrity
+				require.Equal(t, clone, pkt)
+			}
+
+			require.Equal(t, ca.nalus, nalus)
+		})
+	}
+}
+
+func TestDecodeUntilMarkerDONReorder(t *testing.T) {
+	d := &Decoder{MaxDONDiff: 2}
+	d.Init()
+
+	_, _, err := d.DecodeUntilMarker(&rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			Marker:         false,
+			PayloadType:    96,
+			SequenceNumber: 17645,
+			Timestamp:      2289527317,
+			SSRC:           0x9dbb7812,
+		},
+		Payload: []byte{
+			0x60, 0x00, 0x00, 0x01, 0x00, 0x02, 0x08, 0x08,
+			0x00, 0x00, 0x02, 0x09, 0x09,
+		},
+	})
+	require.Equal(t, ErrMorePacketsNeeded, err)
+
+	nalus, _, err := d.DecodeUntilMarker(&rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			Marker:         true,
+			PayloadType:    96,
+			SequenceNumber: 17646,
+			Timestamp:      2289527317,
+			SSRC:           0x9dbb7812,
+		},
+		Payload: []byte{0x07, 0x07, 0x00, 0x00, 0x07},
+	})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{
+		{0x07, 0x07, 0x07},
+		{0x08, 0x08},
+		{0x09, 0x09},
+	}, nalus)
+}
+
 func TestDecoderErrorLimit(t *testing.T) {
 	d := &Decoder{}
 	d.Init()
@@ -47,6 +47,7 @@ type Encoder struct {
 
 	sequenceNumber uint16
 	timeEncoder    *rtptime.Encoder
+	don            uint16
 }
 
 // Init initializes the encoder.
@@ -73,10 +74,6 @@ func (e *Encoder) Init() {
 
 // Encode encodes NALUs into RTP/H265 packets.
 func (e *Encoder) Encode(nalus [][]byte, pts time.Duration) ([]*rtp.Packet, error) {
-	if e.MaxDONDiff != 0 {
-		return nil, fmt.Errorf("MaxDONDiff != 0 is not supported (yet)")
-	}
-
 	var rets []*rtp.Packet
 	var batch [][]byte
 
@@ -114,7 +111,7 @@ func (e *Encoder) Encode(nalus [][]byte, pts time.Duration) ([]*rtp.Packet, erro
 func (e *Encoder) writeBatch(nalus [][]byte, pts time.Duration, marker bool) ([]*rtp.Packet, error) {
 	if len(nalus) == 1 {
 		// the NALU fits into a single RTP packet
-		if len(nalus[0]) < e.PayloadMaxSize {
+		if (len(nalus[0]) + e.lenDONL()) < e.PayloadMaxSize {
 			return e.writeSingle(nalus[0], pts, marker)
 		}
 
@@ -125,7 +122,36 @@ func (e *Encoder) writeBatch(nalus [][]byte, pts time.Duration, marker bool) ([]
 	return e.writeAggregationUnit(nalus, pts, marker)
 }
 
+func (e *Encoder) lenDONL() int {
+	if e.MaxDONDiff != 0 {
+		return 2
+	}
+	return 0
+}
+
+// nextDON returns the decoding order number of the next NALU.
+// NALUs are always sent in decoding order, therefore it is incremented by one for every NALU.
+func (e *Encoder) nextDON() uint16 {
+	v := e.don
+	e.don++
+	return v
+}
+
 func (e *Encoder) writeSingle(nalu []byte, pts time.Duration, marker bool) ([]*rtp.Packet, error) {
+	if e.MaxDONDiff != 0 {
+		if len(nalu) < 2 {
+			return nil, fmt.Errorf("NALU is too short")
+		}
+
+		don := e.nextDON()
+		payload := make([]byte, len(nalu)+2)
+		copy(payload, nalu[:2])
+		payload[2] = byte(don >> 8)
+		payload[3] = byte(don)
+		copy(payload[4:], nalu[2:])
+		nalu = payload
+	}
+
 	pkt := &rtp.Packet{
 		Header: rtp.Header{
 			Version:        rtpVersion,
@@ -144,8 +170,17 @@ func (e *Encoder) writeSingle(nalu []byte, pts time.Duration, marker bool) ([]*r
 }
 
 func (e *Encoder) writeFragmentationUnits(nalu []byte, pts time.Duration, marker bool) ([]*rtp.Packet, error) {
+	head := nalu[:2]
+	nalu = nalu[2:]
+
+	// DONL is placed at the beginning of the first fragment
+	if e.MaxDONDiff != 0 {
+		don := e.nextDON()
+		nalu = append([]byte{byte(don >> 8), byte(don)}, nalu...)
+	}
+
 	avail := e.PayloadMaxSize - 3
-	le := len(nalu) - 2
+	le := len(nalu)
 	n := le / avail
 	lastPacketSize := le % avail
 	if lastPacketSize > 0 {
@@ -155,9 +190,6 @@ func (e *Encoder) writeFragmentationUnits(nalu []byte, pts time.Duration, marker
 	ret := make([]*rtp.Packet, n)
 	encPTS := e.timeEncoder.Encode(pts)
 
-	head := nalu[:2]
-	nalu = nalu[2:]
-
 	for i := range ret {
 		start := uint8(0)
 		if i == 0 {
@@ -198,19 +230,34 @@ func (e *Encoder) writeFragmentationUnits(nalu []byte, pts time.Duration, marker
 func (e *Encoder) lenAggregationUnit(nalus [][]byte, addNALU []byte) int {
 	ret := 2 // header
 
-	for _, nalu := range nalus {
-		ret += 2         // size
-		ret += len(nalu) // nalu
+	for i, nalu := range nalus {
+		ret += e.lenDONField(i) // DONL or DOND
+		ret += 2                // size
+		ret += len(nalu)        // nalu
 	}
 
 	if addNALU != nil {
-		ret += 2            // size
-		ret += len(addNALU) // nalu
+		ret += e.lenDONField(len(nalus)) // DONL or DOND
+		ret += 2                         // size
+		ret += len(addNALU)              // nalu
 	}
 
 	return ret
 }
 
+// lenDONField returns the size of the decoding order field
+// that precedes the i-th NALU of an aggregation unit.
+func (e *Encoder) lenDONField(i int) int {
+	switch {
+	case e.MaxDONDiff == 0:
+		return 0
+	case i == 0:
+		return 2 // DONL
+	default:
+		return 1 // DOND
+	}
+}
+
 func (e *Encoder) writeAggregationUnit(nalus [][]byte, pts time.Duration, marker bool) ([]*rtp.Packet, error) {
 	payload := make([]byte, e.lenAggregationUnit(nalus, nil))
 
@@ -220,7 +267,22 @@ func (e *Encoder) writeAggregationUnit(nalus [][]byte, pts time.Duration, marker
 	payload[1] = byte(h)
 	pos := 2
 
-	for _, nalu := range nalus {
+	for i, nalu := range nalus {
+		if e.MaxDONDiff != 0 {
+			don := e.nextDON()
+
+			if i == 0 {
+				// DONL
+				payload[pos] = byte(don >> 8)
+				payload[pos+1] = byte(don)
+				pos += 2
+			} else {
+				// DOND, always zero since NALUs are sent in decoding order
+				payload[pos] = 0
+				pos++
+			}
+		}
+
 		// size
 		naluLen := len(nalu)
 		payload[pos] = uint8(naluLen >> 8)
@@ -123,6 +123,107 @@ var cases = []struct {
 	},
 }
 
+var casesDON = []struct {
+	name  string
+	nalus [][]byte
+	pkts  []*rtp.Packet
+}{
+	{
+		"single",
+		[][]byte{{0x01, 0x02, 0x03, 0x04, 0x05}},
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         true,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					Timestamp:      2289526357,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: []byte{0x01, 0x02, 0x00, 0x00, 0x03, 0x04, 0x05},
+			},
+		},
+	},
+	{
+		"aggregated",
+		[][]byte{
+			{0x07, 0x07},
+			{0x08, 0x08},
+			{0x09, 0x09},
+		},
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         true,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					Timestamp:      2289526357,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: []byte{
+					0x60, 0x00, 0x00, 0x00, 0x00, 0x02, 0x07, 0x07,
+					0x00, 0x00, 0x02, 0x08, 0x08, 0x00, 0x00, 0x02,
+					0x09, 0x09,
+				},
+			},
+		},
+	},
+	{
+		"fragmented",
+		[][]byte{
+			bytes.Repeat([]byte{0x01, 0x02, 0x03, 0x04}, 1024),
+		},
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					Timestamp:      2289526357,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: mergeBytes(
+					[]byte{0x63, 0x02, 0x80, 0x00, 0x00, 0x03, 0x04},
+					bytes.Repeat([]byte{0x01, 0x02, 0x03, 0x04}, 363),
+					[]byte{0x01},
+				),
+			},
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17646,
+					Timestamp:      2289526357,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: mergeBytes(
+					[]byte{0x63, 0x02, 0x00, 0x02, 0x03, 0x04},
+					bytes.Repeat([]byte{0x01, 0x02, 0x03, 0x04}, 363),
+					[]byte{0x01, 0x02},
+				),
+			},
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         true,
+					PayloadType:    96,
+					SequenceNumber: 17647,
+					Timestamp:      2289526357,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: mergeBytes(
+					[]byte{0x63, 0x02, 0x40, 0x03, 0x04},
+					bytes.Repeat([]byte{0x01, 0x02, 0x03, 0x04}, 295),
+				),
+			},
+		},
+	},
+}
+
 func TestEncode(t *testing.T) {
 	for _, ca := range cases {
 		t.Run(ca.name, func(t *testing.T) {
@@ -150,6 +251,45 @@ func TestEncode(t *testing.T) {
 	}
 }
 
+func TestEncodeDON(t *testing.T) {
+	for _, ca := range casesDON {
+		t.Run(ca.name, func(t *testing.T) {
+			e := &Encoder{
+				PayloadType: 96,
+				SSRC: func() *uint32 {
+					v := uint32(0x9dbb7812)
+					return &v
+				}(),
+				InitialSequenceNumber: func() *uint16 {
+					v := uint16(0x44ed)
+					return &v
+				}(),
+				InitialTimestamp: func() *uint32 {
+					v := uint32(0x88776655)
+					return &v
+				}(),
+				MaxDONDiff: 2,
+			}
+			e.Init()
+
+			pkts, err := e.Encode(ca.nalus, 0)
+			require.NoError(t, err)
+			require.Equal(t, ca.pkts, pkts)
+		})
+	}
+}
+
+func TestEncodeDONErrorShortNALU(t *testing.T) {
+	e := &Encoder{
+		PayloadType: 96,
+		MaxDONDiff:  2,
+	}
+	e.Init()
+
+	_, err := e.Encode([][]byte{{0x01}}, 0)
+	require.EqualError(t, err, "NALU is too short")
+}
+
 func TestEncodeRandomInitialState(t *testing.T) {
 	e := &Encoder{
 		PayloadType: 96,
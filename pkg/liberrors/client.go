@@ -89,6 +89,14 @@ func (e ErrClientUDPPortsNotConsecutive) Error() string {
 	return "rtcpPort must be rtpPort + 1"
 }
 
+// ErrClientUDPNotSupportedByConn is an error that can be returned by a client.
+type ErrClientUDPNotSupportedByConn struct{}
+
+// Error implements the error interface.
+func (e ErrClientUDPNotSupportedByConn) Error() string {
+	return "UDP transport can't be used with connections that are not IP-based"
+}
+
 // ErrClientServerPortsNotProvided is an error that can be returned by a client.
 type ErrClientServerPortsNotProvided struct{}
 
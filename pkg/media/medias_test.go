@@ -261,7 +261,7 @@ var casesMedias = []struct {
 			"a=control\r\n" +
 			"a=sendonly\r\n" +
 			"a=rtpmap:111 opus/48000/2\r\n" +
-			"a=fmtp:111 sprop-stereo=0\r\n" +
+			"a=fmtp:111 sprop-stereo=0; useinbandfec=1\r\n" +
 			"a=rtpmap:103 ISAC/16000\r\n" +
 			"a=rtpmap:104 ISAC/32000\r\n" +
 			"a=rtpmap:9 G722/8000\r\n" +
@@ -297,8 +297,9 @@ var casesMedias = []struct {
 				Direction: DirectionSendonly,
 				Formats: []formats.Format{
 					&formats.Opus{
-						PayloadTyp: 111,
-						IsStereo:   false,
+						PayloadTyp:   111,
+						IsStereo:     false,
+						UseInbandFEC: true,
 					},
 					&formats.Generic{
 						PayloadTyp: 103,
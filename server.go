@@ -46,6 +46,10 @@ type streamMulticastIPReq struct {
 }
 
 // Server is a RTSP server.
+//
+// The underlying transport can be replaced with any net.Listener and net.PacketConn
+// (for instance, in-memory pipes or tunnels) through Listen and ListenPacket;
+// there's no dedicated transport interface.
 type Server struct {
 	//
 	// RTSP parameters (all optional except RTSPAddress)
@@ -104,6 +108,8 @@ type Server struct {
 	// system functions (all optional)
 	//
 	// function used to initialize the TCP listener.
+	// It can return any net.Listener, including in-memory or custom links;
+	// connections whose addresses don't contain an IP can only use the TCP transport.
 	// It defaults to net.Listen.
 	Listen func(network string, address string) (net.Listener, error)
 	// function used to initialize UDP listeners.
@@ -376,8 +382,7 @@ func (s *Server) run() {
 
 			case req := <-s.sessionRequest:
 				if ss, ok := s.sessions[req.id]; ok {
-					if !req.sc.ip().Equal(ss.author.ip()) ||
-						req.sc.zone() != ss.author.zone() {
+					if !req.sc.sameRemote(ss.author) {
 						req.res <- sessionRequestRes{
 							res: &base.Response{
 								StatusCode: base.StatusBadRequest,
@@ -96,7 +96,7 @@ func newServerConn(
 		bc:            bytecounter.New(nconn, nil, nil),
 		ctx:           ctx,
 		ctxCancel:     ctxCancel,
-		remoteAddr:    nconn.RemoteAddr().(*net.TCPAddr),
+		remoteAddr:    tcpAddr(nconn.RemoteAddr()),
 		sessionRemove: make(chan *ServerSession),
 		done:          make(chan struct{}),
 	}
@@ -148,6 +148,16 @@ func (sc *ServerConn) zone() string {
 	return sc.remoteAddr.Zone
 }
 
+// sameRemote checks whether two connections come from the same remote host.
+// Connections that are not IP-based are compared by identity.
+func (sc *ServerConn) sameRemote(other *ServerConn) bool {
+	if sc.ip() == nil || other.ip() == nil {
+		return sc == other
+	}
+
+	return sc.ip().Equal(other.ip()) && sc.zone() == other.zone()
+}
+
 func (sc *ServerConn) run() {
 	defer sc.s.wg.Done()
 	defer close(sc.done)
@@ -283,17 +293,10 @@ func (sc *ServerConn) readFuncTCP(readRequest chan readReq) error {
 
 		switch twhat := what.(type) {
 		case *base.InterleavedFrame:
-			channel := twhat.Channel
-			isRTP := true
-			if (channel % 2) != 0 {
-				channel--
-				isRTP = false
-			}
-
 			atomic.AddUint64(sc.session.bytesReceived, uint64(len(twhat.Payload)))
 
-			if sm, ok := sc.session.tcpMediasByChannel[channel]; ok {
-				if isRTP {
+			if sm, ok := sc.session.tcpMediasByChannel[twhat.Channel]; ok {
+				if twhat.Channel == sm.tcpRTPChannel {
 					sm.readRTP(twhat.Payload)
 				} else {
 					sm.readRTCP(twhat.Payload)
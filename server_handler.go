@@ -119,6 +119,25 @@ type ServerHandlerOnSetup interface {
 	OnSetup(*ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error)
 }
 
+// ServerHandlerOnSetupInterleavedIDsCtx is the context of OnSetupInterleavedIDs.
+type ServerHandlerOnSetupInterleavedIDsCtx struct {
+	Session *ServerSession
+	Conn    *ServerConn
+	Media   *media.Media
+	// interleaved IDs requested by the client, or nil.
+	Requested *[2]int
+}
+
+// ServerHandlerOnSetupInterleavedIDs can be implemented by a ServerHandler.
+type ServerHandlerOnSetupInterleavedIDs interface {
+	// called when a media is being setup with the TCP transport, before the session is modified.
+	// must return the interleaved IDs of RTP and RTCP packets of the media,
+	// that are sent back to the client.
+	// Requested IDs may already be in use by another media, in which case they must be replaced.
+	// Returned IDs must be different and can't be already in use by another media.
+	OnSetupInterleavedIDs(*ServerHandlerOnSetupInterleavedIDsCtx) [2]int
+}
+
 // ServerHandlerOnPlayCtx is the context of OnPlay.
 type ServerHandlerOnPlayCtx struct {
 	Session *ServerSession
@@ -2046,3 +2046,245 @@ func TestServerPlayNoInterleavedIDs(t *testing.T) {
 		require.Equal(t, testRTPPacketMarshaled, f.Payload)
 	}
 }
+
+type testServerHandlerInterleavedIDs struct {
+	testServerHandler
+	onSetupInterleavedIDs func(*ServerHandlerOnSetupInterleavedIDsCtx) [2]int
+}
+
+func (sh *testServerHandlerInterleavedIDs) OnSetupInterleavedIDs(
+	ctx *ServerHandlerOnSetupInterleavedIDsCtx,
+) [2]int {
+	return sh.onSetupInterleavedIDs(ctx)
+}
+
+func TestServerPlayCustomInterleavedIDs(t *testing.T) {
+	forma := &formats.Generic{
+		PayloadTyp: 96,
+		RTPMa:      "private/90000",
+	}
+	err := forma.Init()
+	require.NoError(t, err)
+
+	stream := NewServerStream(media.Medias{
+		&media.Media{
+			Type:    "application",
+			Formats: []formats.Format{forma},
+		},
+		&media.Media{
+			Type:    "application",
+			Formats: []formats.Format{forma},
+		},
+	})
+	defer stream.Close()
+
+	ids := map[*media.Media][2]int{
+		stream.Medias()[0]: {9, 4},
+		stream.Medias()[1]: {5, 12},
+	}
+
+	s := &Server{
+		Handler: &testServerHandlerInterleavedIDs{
+			testServerHandler: testServerHandler{
+				onDescribe: func(ctx *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+					return &base.Response{
+						StatusCode: base.StatusOK,
+					}, stream, nil
+				},
+				onSetup: func(ctx *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+					return &base.Response{
+						StatusCode: base.StatusOK,
+					}, stream, nil
+				},
+				onPlay: func(ctx *ServerHandlerOnPlayCtx) (*base.Response, error) {
+					return &base.Response{
+						StatusCode: base.StatusOK,
+					}, nil
+				},
+			},
+			onSetupInterleavedIDs: func(ctx *ServerHandlerOnSetupInterleavedIDsCtx) [2]int {
+				require.Equal(t, &[2]int{0, 1}, ctx.Requested)
+				return ids[ctx.Media]
+			},
+		},
+		RTSPAddress: "localhost:8554",
+	}
+
+	err = s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	nconn, err := net.Dial("tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer nconn.Close()
+	conn := conn.NewConn(nconn)
+
+	desc := doDescribe(t, conn)
+
+	inTH := &headers.Transport{
+		Delivery: func() *headers.TransportDelivery {
+			v := headers.TransportDeliveryUnicast
+			return &v
+		}(),
+		Mode: func() *headers.TransportMode {
+			v := headers.TransportModePlay
+			return &v
+		}(),
+		Protocol:       headers.TransportProtocolTCP,
+		InterleavedIDs: &[2]int{0, 1},
+	}
+
+	res, th := doSetup(t, conn, absoluteControlAttribute(desc.MediaDescriptions[0]), inTH, "")
+
+	require.Equal(t, &[2]int{9, 4}, th.InterleavedIDs)
+
+	session := readSession(t, res)
+
+	_, th = doSetup(t, conn, absoluteControlAttribute(desc.MediaDescriptions[1]), inTH, session)
+
+	require.Equal(t, &[2]int{5, 12}, th.InterleavedIDs)
+
+	doPlay(t, conn, "rtsp://localhost:8554/teststream", session)
+
+	for i := 0; i < 2; i++ {
+		stream.WritePacketRTP(stream.Medias()[i], &testRTPPacket)
+
+		f, err := conn.ReadInterleavedFrame()
+		require.NoError(t, err)
+		require.Equal(t, ids[stream.Medias()[i]][0], f.Channel)
+		require.Equal(t, testRTPPacketMarshaled, f.Payload)
+	}
+}
+
+// doSetupInvalidOtherConn sends a SETUP request that is expected to fail.
+// since errors cause the connection to be closed, the request is sent
+// through a secondary connection.
+func doSetupInvalidOtherConn(t *testing.T, u string, inTH *headers.Transport, session string) {
+	nconn, err := net.Dial("tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer nconn.Close()
+	conn := conn.NewConn(nconn)
+
+	h := base.Header{
+		"CSeq":      base.HeaderValue{"1"},
+		"Transport": inTH.Marshal(),
+	}
+
+	if session != "" {
+		h["Session"] = base.HeaderValue{session}
+	}
+
+	res, err := writeReqReadRes(conn, base.Request{
+		Method: base.Setup,
+		URL:    mustParseURL(u),
+		Header: h,
+	})
+	require.NoError(t, err)
+	require.Equal(t, base.StatusBadRequest, res.StatusCode)
+}
+
+func TestServerPlayCustomInterleavedIDsInvalid(t *testing.T) {
+	forma := &formats.Generic{
+		PayloadTyp: 96,
+		RTPMa:      "private/90000",
+	}
+	err := forma.Init()
+	require.NoError(t, err)
+
+	stream := NewServerStream(media.Medias{
+		&media.Media{
+			Type:    "application",
+			Formats: []formats.Format{forma},
+		},
+		&media.Media{
+			Type:    "application",
+			Formats: []formats.Format{forma},
+		},
+	})
+	defer stream.Close()
+
+	// invalid, valid, already in use, valid
+	ids := [][2]int{{3, 3}, {0, 1}, {1, 2}, {2, 3}}
+
+	s := &Server{
+		Handler: &testServerHandlerInterleavedIDs{
+			testServerHandler: testServerHandler{
+				onDescribe: func(ctx *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+					return &base.Response{
+						StatusCode: base.StatusOK,
+					}, stream, nil
+				},
+				onSetup: func(ctx *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+					return &base.Response{
+						StatusCode: base.StatusOK,
+					}, stream, nil
+				},
+				onPlay: func(ctx *ServerHandlerOnPlayCtx) (*base.Response, error) {
+					return &base.Response{
+						StatusCode: base.StatusOK,
+					}, nil
+				},
+			},
+			onSetupInterleavedIDs: func(ctx *ServerHandlerOnSetupInterleavedIDsCtx) [2]int {
+				require.Equal(t, &[2]int{0, 1}, ctx.Requested)
+				ret := ids[0]
+				ids = ids[1:]
+				return ret
+			},
+		},
+		RTSPAddress: "localhost:8554",
+	}
+
+	err = s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	nconn, err := net.Dial("tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer nconn.Close()
+	conn := conn.NewConn(nconn)
+
+	desc := doDescribe(t, conn)
+
+	inTH := &headers.Transport{
+		Delivery: func() *headers.TransportDelivery {
+			v := headers.TransportDeliveryUnicast
+			return &v
+		}(),
+		Mode: func() *headers.TransportMode {
+			v := headers.TransportModePlay
+			return &v
+		}(),
+		Protocol:       headers.TransportProtocolTCP,
+		InterleavedIDs: &[2]int{0, 1},
+	}
+
+	doSetupInvalidOtherConn(t, absoluteControlAttribute(desc.MediaDescriptions[0]), inTH, "")
+
+	// the session must not have been added to the stream
+	stream.mutex.RLock()
+	require.Equal(t, 0, len(stream.readers))
+	stream.mutex.RUnlock()
+
+	res, th := doSetup(t, conn, absoluteControlAttribute(desc.MediaDescriptions[0]), inTH, "")
+	require.Equal(t, &[2]int{0, 1}, th.InterleavedIDs)
+
+	session := readSession(t, res)
+
+	// requested IDs are already in use, but they are replaced by the handler
+	doSetupInvalidOtherConn(t, absoluteControlAttribute(desc.MediaDescriptions[1]), inTH, session)
+
+	_, th = doSetup(t, conn, absoluteControlAttribute(desc.MediaDescriptions[1]), inTH, session)
+	require.Equal(t, &[2]int{2, 3}, th.InterleavedIDs)
+
+	doPlay(t, conn, "rtsp://localhost:8554/teststream", session)
+
+	for i := 0; i < 2; i++ {
+		stream.WritePacketRTP(stream.Medias()[i], &testRTPPacket)
+
+		f, err := conn.ReadInterleavedFrame()
+		require.NoError(t, err)
+		require.Equal(t, i*2, f.Channel)
+		require.Equal(t, testRTPPacketMarshaled, f.Payload)
+	}
+}
@@ -2,6 +2,7 @@ package gortsplib
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"net"
 	"strconv"
@@ -1375,3 +1376,128 @@ func TestServerRecordDecodeErrors(t *testing.T) {
 		})
 	}
 }
+
+type testPipeListener struct {
+	conns chan net.Conn
+	done  chan struct{}
+}
+
+func newTestPipeListener() *testPipeListener {
+	return &testPipeListener{
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+func (l *testPipeListener) Accept() (net.Conn, error) {
+	select {
+	case nconn := <-l.conns:
+		return nconn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *testPipeListener) Close() error {
+	close(l.done)
+	return nil
+}
+
+func (l *testPipeListener) Addr() net.Addr {
+	return &net.UnixAddr{Name: "pipe", Net: "pipe"}
+}
+
+func (l *testPipeListener) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	c1, c2 := net.Pipe()
+
+	select {
+	case l.conns <- c2:
+		return c1, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestServerRecordCustomConn(t *testing.T) {
+	for _, ca := range []struct {
+		name      string
+		transport *Transport
+		err       string
+	}{
+		{
+			"auto",
+			nil,
+			"",
+		},
+		{
+			"udp",
+			func() *Transport {
+				v := TransportUDP
+				return &v
+			}(),
+			"UDP transport can't be used with connections that are not IP-based",
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			l := newTestPipeListener()
+			packetRecv := make(chan struct{})
+
+			s := &Server{
+				Handler: &testServerHandler{
+					onAnnounce: func(ctx *ServerHandlerOnAnnounceCtx) (*base.Response, error) {
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, nil
+					},
+					onSetup: func(ctx *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+						require.Equal(t, TransportTCP, ctx.Transport)
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, nil, nil
+					},
+					onRecord: func(ctx *ServerHandlerOnRecordCtx) (*base.Response, error) {
+						ctx.Session.OnPacketRTPAny(func(medi *media.Media, forma formats.Format, pkt *rtp.Packet) {
+							require.Equal(t, &testRTPPacket, pkt)
+							close(packetRecv)
+						})
+
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, nil
+					},
+				},
+				RTSPAddress:    "localhost:8554",
+				UDPRTPAddress:  "127.0.0.1:8000",
+				UDPRTCPAddress: "127.0.0.1:8001",
+				Listen: func(network string, address string) (net.Listener, error) {
+					return l, nil
+				},
+			}
+
+			err := s.Start()
+			require.NoError(t, err)
+			defer s.Close()
+
+			c := Client{
+				Transport:   ca.transport,
+				DialContext: l.dial,
+			}
+
+			medi := testH264Media
+			err = c.StartRecording("rtsp://localhost:8554/teststream", media.Medias{medi})
+			if ca.err != "" {
+				require.EqualError(t, err, ca.err)
+				return
+			}
+			require.NoError(t, err)
+			defer c.Close()
+
+			err = c.WritePacketRTP(medi, &testRTPPacket)
+			require.NoError(t, err)
+
+			<-packetRecv
+		})
+	}
+}
@@ -114,23 +114,41 @@ func findAndValidateTransport(inTH *headers.Transport,
 	}
 
 	if inTH.InterleavedIDs != nil {
-		if (inTH.InterleavedIDs[0]%2) != 0 ||
-			(inTH.InterleavedIDs[0]+1) != inTH.InterleavedIDs[1] {
-			return 0, liberrors.ErrServerTransportHeaderInvalidInterleavedIDs{}
+		err := validateInterleavedIDs(*inTH.InterleavedIDs, tcpMediasByChannel)
+		if err != nil {
+			return 0, err
 		}
+	}
+
+	return TransportTCP, nil
+}
+
+// validateInterleavedIDs checks that interleaved IDs are valid channels and are not in use.
+// RTP and RTCP channels are not required to be even, consecutive or in any particular order.
+func validateInterleavedIDs(ids [2]int,
+	tcpMediasByChannel map[int]*serverSessionMedia,
+) error {
+	if ids[0] < 0 || ids[0] > 255 ||
+		ids[1] < 0 || ids[1] > 255 ||
+		ids[0] == ids[1] {
+		return liberrors.ErrServerTransportHeaderInvalidInterleavedIDs{}
+	}
 
-		if _, ok := tcpMediasByChannel[inTH.InterleavedIDs[0]]; ok {
-			return 0, liberrors.ErrServerTransportHeaderInterleavedIDsAlreadyUsed{}
+	for _, id := range ids {
+		if _, ok := tcpMediasByChannel[id]; ok {
+			return liberrors.ErrServerTransportHeaderInterleavedIDsAlreadyUsed{}
 		}
 	}
 
-	return TransportTCP, nil
+	return nil
 }
 
-func findFreeChannel(tcpMediasByChannel map[int]*serverSessionMedia) int {
+func findFreeChannelPair(tcpMediasByChannel map[int]*serverSessionMedia) [2]int {
 	for i := 0; ; i += 2 {
-		if _, ok := tcpMediasByChannel[i]; !ok {
-			return i
+		_, ok1 := tcpMediasByChannel[i]
+		_, ok2 := tcpMediasByChannel[i+1]
+		if !ok1 && !ok2 {
+			return [2]int{i, i + 1}
 		}
 	}
 }
@@ -671,7 +689,14 @@ func (ss *ServerSession) handleRequest(sc *ServerConn, req *base.Request) (*base
 			query = ss.setuppedQuery
 		}
 
-		transport, err := findAndValidateTransport(inTH, ss.tcpMediasByChannel)
+		// when IDs are chosen by the handler, the ones requested by the client
+		// can be already in use, since they are going to be replaced.
+		tcpMediasByChannel := ss.tcpMediasByChannel
+		if _, ok := ss.s.Handler.(ServerHandlerOnSetupInterleavedIDs); ok {
+			tcpMediasByChannel = nil
+		}
+
+		transport, err := findAndValidateTransport(inTH, tcpMediasByChannel)
 		if err != nil {
 			return &base.Response{
 				StatusCode: base.StatusBadRequest,
@@ -684,6 +709,13 @@ func (ss *ServerSession) handleRequest(sc *ServerConn, req *base.Request) (*base
 			}, liberrors.ErrServerMediasDifferentProtocols{}
 		}
 
+		// UDP requires an IP-based connection
+		if transport != TransportTCP && sc.ip() == nil {
+			return &base.Response{
+				StatusCode: base.StatusUnsupportedTransport,
+			}, nil
+		}
+
 		switch ss.state {
 		case ServerSessionStateInitial, ServerSessionStatePrePlay: // play
 			if inTH.Mode != nil && *inTH.Mode != headers.TransportModePlay {
@@ -765,6 +797,30 @@ func (ss *ServerSession) handleRequest(sc *ServerConn, req *base.Request) (*base
 			}, liberrors.ErrServerMediaAlreadySetup{}
 		}
 
+		var interleavedIDs [2]int
+
+		if transport == TransportTCP {
+			if h, ok := ss.s.Handler.(ServerHandlerOnSetupInterleavedIDs); ok {
+				interleavedIDs = h.OnSetupInterleavedIDs(&ServerHandlerOnSetupInterleavedIDsCtx{
+					Session:   ss,
+					Conn:      sc,
+					Media:     medi,
+					Requested: inTH.InterleavedIDs,
+				})
+
+				err := validateInterleavedIDs(interleavedIDs, ss.tcpMediasByChannel)
+				if err != nil {
+					return &base.Response{
+						StatusCode: base.StatusBadRequest,
+					}, err
+				}
+			} else if inTH.InterleavedIDs != nil {
+				interleavedIDs = *inTH.InterleavedIDs
+			} else {
+				interleavedIDs = findFreeChannelPair(ss.tcpMediasByChannel)
+			}
+		}
+
 		if ss.state == ServerSessionStateInitial {
 			err := stream.readerAdd(ss,
 				transport,
@@ -832,22 +888,20 @@ func (ss *ServerSession) handleRequest(sc *ServerConn, req *base.Request) (*base
 			th.Ports = &[2]int{ss.s.MulticastRTPPort, ss.s.MulticastRTCPPort}
 
 		default: // TCP
-			if inTH.InterleavedIDs != nil {
-				sm.tcpChannel = inTH.InterleavedIDs[0]
-			} else {
-				sm.tcpChannel = findFreeChannel(ss.tcpMediasByChannel)
-			}
+			sm.tcpRTPChannel = interleavedIDs[0]
+			sm.tcpRTCPChannel = interleavedIDs[1]
 
 			if ss.tcpMediasByChannel == nil {
 				ss.tcpMediasByChannel = make(map[int]*serverSessionMedia)
 			}
 
-			ss.tcpMediasByChannel[sm.tcpChannel] = sm
+			ss.tcpMediasByChannel[sm.tcpRTPChannel] = sm
+			ss.tcpMediasByChannel[sm.tcpRTCPChannel] = sm
 
 			th.Protocol = headers.TransportProtocolTCP
 			de := headers.TransportDeliveryUnicast
 			th.Delivery = &de
-			th.InterleavedIDs = &[2]int{sm.tcpChannel, sm.tcpChannel + 1}
+			th.InterleavedIDs = &interleavedIDs
 		}
 
 		if ss.setuppedMedias == nil {
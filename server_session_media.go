@@ -16,7 +16,8 @@ import (
 type serverSessionMedia struct {
 	ss                     *ServerSession
 	media                  *media.Media
-	tcpChannel             int
+	tcpRTPChannel          int
+	tcpRTCPChannel         int
 	udpRTPReadPort         int
 	udpRTPWriteAddr        *net.UDPAddr
 	udpRTCPReadPort        int
@@ -80,8 +81,8 @@ func (sm *serverSessionMedia) start() {
 			sm.readRTCP = sm.readRTCPTCPRecord
 		}
 
-		sm.tcpRTPFrame = &base.InterleavedFrame{Channel: sm.tcpChannel}
-		sm.tcpRTCPFrame = &base.InterleavedFrame{Channel: sm.tcpChannel + 1}
+		sm.tcpRTPFrame = &base.InterleavedFrame{Channel: sm.tcpRTPChannel}
+		sm.tcpRTCPFrame = &base.InterleavedFrame{Channel: sm.tcpRTCPChannel}
 		sm.tcpBuffer = make([]byte, udpMaxPayloadSize+4)
 	}
 
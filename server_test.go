@@ -1,6 +1,7 @@
 package gortsplib
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"testing"
@@ -10,6 +11,7 @@ import (
 	"github.com/bluenviron/gortsplib/v3/pkg/auth"
 	"github.com/bluenviron/gortsplib/v3/pkg/base"
 	"github.com/bluenviron/gortsplib/v3/pkg/conn"
+	"github.com/bluenviron/gortsplib/v3/pkg/formats"
 	"github.com/bluenviron/gortsplib/v3/pkg/headers"
 	"github.com/bluenviron/gortsplib/v3/pkg/media"
 )
@@ -505,6 +507,155 @@ func TestServerErrorTCPTwoConnOneSession(t *testing.T) {
 	require.Equal(t, base.StatusBadRequest, res.StatusCode)
 }
 
+func TestServerErrorCustomConnTwoConnOneSession(t *testing.T) {
+	forma := &formats.Generic{
+		PayloadTyp: 96,
+		RTPMa:      "private/90000",
+	}
+	err := forma.Init()
+	require.NoError(t, err)
+
+	stream := NewServerStream(media.Medias{
+		&media.Media{
+			Type:    "application",
+			Formats: []formats.Format{forma},
+		},
+		&media.Media{
+			Type:    "application",
+			Formats: []formats.Format{forma},
+		},
+	})
+	defer stream.Close()
+
+	l := newTestPipeListener()
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onDescribe: func(ctx *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onSetup: func(ctx *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+		},
+		RTSPAddress: "localhost:8554",
+		Listen: func(network string, address string) (net.Listener, error) {
+			return l, nil
+		},
+	}
+
+	err = s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	nconn1, err := l.dial(context.Background(), "tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer nconn1.Close()
+	conn1 := conn.NewConn(nconn1)
+
+	desc := doDescribe(t, conn1)
+
+	inTH := &headers.Transport{
+		Protocol: headers.TransportProtocolTCP,
+		Delivery: func() *headers.TransportDelivery {
+			v := headers.TransportDeliveryUnicast
+			return &v
+		}(),
+		Mode: func() *headers.TransportMode {
+			v := headers.TransportModePlay
+			return &v
+		}(),
+	}
+
+	res, _ := doSetup(t, conn1, absoluteControlAttribute(desc.MediaDescriptions[0]), inTH, "")
+
+	session := readSession(t, res)
+
+	// connections that are not IP-based can't be told apart by address,
+	// therefore a session can't be used by other connections.
+	nconn2, err := l.dial(context.Background(), "tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer nconn2.Close()
+	conn2 := conn.NewConn(nconn2)
+
+	res, err = writeReqReadRes(conn2, base.Request{
+		Method: base.Setup,
+		URL:    mustParseURL(absoluteControlAttribute(desc.MediaDescriptions[1])),
+		Header: base.Header{
+			"CSeq":      base.HeaderValue{"1"},
+			"Transport": inTH.Marshal(),
+			"Session":   base.HeaderValue{session},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, base.StatusBadRequest, res.StatusCode)
+}
+
+func TestServerErrorCustomConnUDP(t *testing.T) {
+	stream := NewServerStream(media.Medias{testH264Media})
+	defer stream.Close()
+
+	l := newTestPipeListener()
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onDescribe: func(ctx *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onSetup: func(ctx *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+		},
+		RTSPAddress:    "localhost:8554",
+		UDPRTPAddress:  "127.0.0.1:8000",
+		UDPRTCPAddress: "127.0.0.1:8001",
+		Listen: func(network string, address string) (net.Listener, error) {
+			return l, nil
+		},
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	nconn, err := l.dial(context.Background(), "tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer nconn.Close()
+	conn := conn.NewConn(nconn)
+
+	desc := doDescribe(t, conn)
+
+	res, err := writeReqReadRes(conn, base.Request{
+		Method: base.Setup,
+		URL:    mustParseURL(absoluteControlAttribute(desc.MediaDescriptions[0])),
+		Header: base.Header{
+			"CSeq": base.HeaderValue{"1"},
+			"Transport": headers.Transport{
+				Protocol: headers.TransportProtocolUDP,
+				Delivery: func() *headers.TransportDelivery {
+					v := headers.TransportDeliveryUnicast
+					return &v
+				}(),
+				Mode: func() *headers.TransportMode {
+					v := headers.TransportModePlay
+					return &v
+				}(),
+				ClientPorts: &[2]int{35466, 35467},
+			}.Marshal(),
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, base.StatusUnsupportedTransport, res.StatusCode)
+}
+
 func TestServerErrorTCPOneConnTwoSessions(t *testing.T) {
 	stream := NewServerStream(media.Medias{testH264Media})
 	defer stream.Close()
@@ -39,7 +39,7 @@ func (p *clientAddr) fill(ip net.IP, port int) {
 }
 
 type serverUDPListener struct {
-	pc           *net.UDPConn
+	pc           net.PacketConn
 	listenIP     net.IP
 	isRTP        bool
 	writeTimeout time.Duration
@@ -89,7 +89,7 @@ func newServerUDPListener(
 	address string,
 	isRTP bool,
 ) (*serverUDPListener, error) {
-	var pc *net.UDPConn
+	var pc net.PacketConn
 	var listenIP net.IP
 	if multicast {
 		host, port, err := net.SplitHostPort(address)
@@ -125,18 +125,18 @@ func newServerUDPListener(
 			}
 		}
 
-		pc = tmp.(*net.UDPConn)
+		pc = tmp
 	} else {
 		tmp, err := listenPacket(restrictNetwork("udp", address))
 		if err != nil {
 			return nil, err
 		}
 
-		pc = tmp.(*net.UDPConn)
-		listenIP = tmp.LocalAddr().(*net.UDPAddr).IP
+		pc = tmp
+		listenIP = udpAddr(tmp.LocalAddr()).IP
 	}
 
-	err := pc.SetReadBuffer(udpKernelReadBufferSize)
+	err := setReadBuffer(pc, udpKernelReadBufferSize)
 	if err != nil {
 		return nil, err
 	}
@@ -165,7 +165,7 @@ func (u *serverUDPListener) ip() net.IP {
 }
 
 func (u *serverUDPListener) port() int {
-	return u.pc.LocalAddr().(*net.UDPAddr).Port
+	return udpAddr(u.pc.LocalAddr()).Port
 }
 
 func (u *serverUDPListener) runReader() {
@@ -184,17 +184,19 @@ func (u *serverUDPListener) runReader() {
 
 	for {
 		buf := make([]byte, udpMaxPayloadSize+1)
-		n, addr, err := u.pc.ReadFromUDP(buf)
+		n, addr, err := u.pc.ReadFrom(buf)
 		if err != nil {
 			break
 		}
 
+		uaddr := udpAddr(addr)
+
 		func() {
 			u.clientsMutex.RLock()
 			defer u.clientsMutex.RUnlock()
 
 			var clientAddr clientAddr
-			clientAddr.fill(addr.IP, addr.Port)
+			clientAddr.fill(uaddr.IP, uaddr.Port)
 			sm, ok := u.clients[clientAddr]
 			if !ok {
 				return